@@ -0,0 +1,56 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+)
+
+// accessLogEntry is one structured JSON line written per proxied request.
+// TraceID lets logs be correlated with the spans emitted alongside them.
+type accessLogEntry struct {
+	Timestamp     time.Time `json:"timestamp"`
+	Service       string    `json:"service"`
+	Method        string    `json:"method"`
+	Path          string    `json:"path"`
+	UpstreamURL   string    `json:"upstream_url"`
+	StatusCode    int       `json:"status_code"`
+	DurationMs    int64     `json:"duration_ms"`
+	ClientIP      string    `json:"client_ip"`
+	UserAgent     string    `json:"user_agent"`
+	RequestBytes  int64     `json:"request_bytes"`
+	ResponseBytes int       `json:"response_bytes"`
+	TraceID       string    `json:"trace_id,omitempty"`
+}
+
+// accessLogger writes accessLogEntry values as newline-delimited JSON to
+// stdout, or to the file at ACCESS_LOG_PATH when set.
+type accessLogger struct {
+	out io.Writer
+}
+
+func newAccessLogger() *accessLogger {
+	path := os.Getenv("ACCESS_LOG_PATH")
+	if path == "" {
+		return &accessLogger{out: os.Stdout}
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		log.Printf("warning: failed to open ACCESS_LOG_PATH %s, logging to stdout: %v", path, err)
+		return &accessLogger{out: os.Stdout}
+	}
+	return &accessLogger{out: f}
+}
+
+func (a *accessLogger) log(entry accessLogEntry) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		log.Printf("warning: failed to marshal access log entry: %v", err)
+		return
+	}
+	fmt.Fprintln(a.out, string(data))
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestAccessLoggerWritesOneJSONLinePerEntry(t *testing.T) {
+	var buf bytes.Buffer
+	logger := &accessLogger{out: &buf}
+
+	logger.log(accessLogEntry{Service: "mcp-service", Method: "GET", StatusCode: 200})
+	logger.log(accessLogEntry{Service: "tools-service", Method: "POST", StatusCode: 500})
+
+	lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 log lines, got %d: %q", len(lines), buf.String())
+	}
+
+	var first accessLogEntry
+	if err := json.Unmarshal([]byte(lines[0]), &first); err != nil {
+		t.Fatalf("first line is not valid JSON: %v", err)
+	}
+	if first.Service != "mcp-service" || first.StatusCode != 200 {
+		t.Errorf("unexpected first entry: %+v", first)
+	}
+
+	var second accessLogEntry
+	if err := json.Unmarshal([]byte(lines[1]), &second); err != nil {
+		t.Fatalf("second line is not valid JSON: %v", err)
+	}
+	if second.Service != "tools-service" || second.StatusCode != 500 {
+		t.Errorf("unexpected second entry: %+v", second)
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"crypto/subtle"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/cmndcntrlcyber/offsec-team/gateway/auth"
+	"github.com/gin-gonic/gin"
+)
+
+// adminKeyMiddleware protects the /admin/keys routes with a single admin
+// key read from GATEWAY_ADMIN_KEY, checked against the same
+// Authorization: Bearer header the proxy routes use. An unset admin key
+// disables the routes entirely rather than leaving them open.
+func adminKeyMiddleware() gin.HandlerFunc {
+	adminKey := os.Getenv("GATEWAY_ADMIN_KEY")
+	if adminKey == "" {
+		log.Printf("warning: GATEWAY_ADMIN_KEY is not set, /admin/keys is disabled")
+	}
+
+	return func(c *gin.Context) {
+		presented := bearerAdminToken(c)
+		if adminKey == "" || presented == "" ||
+			subtle.ConstantTimeCompare([]byte(presented), []byte(adminKey)) != 1 {
+			c.JSON(401, gin.H{"error": "unauthorized"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+func bearerAdminToken(c *gin.Context) string {
+	const prefix = "Bearer "
+	header := c.GetHeader("Authorization")
+	if !strings.HasPrefix(header, prefix) {
+		return ""
+	}
+	return strings.TrimPrefix(header, prefix)
+}
+
+// registerAdminKeyRoutes wires up CRUD for the gateway's static API keys
+// under r (expected to already be rooted at /admin), so they can be issued
+// and rotated without a restart.
+func registerAdminKeyRoutes(r gin.IRoutes, keys *auth.KeyStore) {
+	r.GET("/keys", func(c *gin.Context) {
+		c.JSON(200, gin.H{"keys": keys.List()})
+	})
+
+	type createKeyRequest struct {
+		Name   string   `json:"name"`
+		Scopes []string `json:"scopes"`
+	}
+	r.POST("/keys", func(c *gin.Context) {
+		var req createKeyRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+		if req.Name == "" {
+			c.JSON(400, gin.H{"error": "name is required"})
+			return
+		}
+
+		key, secret, err := keys.Create(req.Name, req.Scopes)
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(201, gin.H{"key": key, "secret": secret})
+	})
+
+	r.POST("/keys/:id/rotate", func(c *gin.Context) {
+		secret, err := keys.Rotate(c.Param("id"))
+		if err != nil {
+			c.JSON(404, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(200, gin.H{"secret": secret})
+	})
+
+	r.DELETE("/keys/:id", func(c *gin.Context) {
+		if err := keys.Delete(c.Param("id")); err != nil {
+			c.JSON(404, gin.H{"error": err.Error()})
+			return
+		}
+		c.Status(204)
+	})
+}
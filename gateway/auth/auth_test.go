@@ -0,0 +1,161 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRuleForExactPrefixWinsOverWildcard(t *testing.T) {
+	a := &Authenticator{rules: []Rule{
+		{Prefix: "*", Schemes: []string{SchemeJWT}},
+		{Prefix: "mcp-service", Schemes: []string{SchemeAPIKey}},
+	}}
+
+	rule, ok := a.ruleFor("mcp-service")
+	if !ok || len(rule.Schemes) != 1 || rule.Schemes[0] != SchemeAPIKey {
+		t.Fatalf("expected the exact-match rule, got %+v ok=%v", rule, ok)
+	}
+}
+
+func TestRuleForFallsBackToWildcard(t *testing.T) {
+	a := &Authenticator{rules: []Rule{
+		{Prefix: "*", Schemes: []string{SchemeJWT}},
+		{Prefix: "mcp-service", Schemes: []string{SchemeAPIKey}},
+	}}
+
+	rule, ok := a.ruleFor("tools-service")
+	if !ok || len(rule.Schemes) != 1 || rule.Schemes[0] != SchemeJWT {
+		t.Fatalf("expected the wildcard rule, got %+v ok=%v", rule, ok)
+	}
+}
+
+func TestRuleForNoMatch(t *testing.T) {
+	a := &Authenticator{rules: []Rule{{Prefix: "mcp-service", Schemes: []string{SchemeAPIKey}}}}
+
+	if _, ok := a.ruleFor("tools-service"); ok {
+		t.Fatal("expected no rule to match")
+	}
+}
+
+func TestScopeAllows(t *testing.T) {
+	tests := []struct {
+		name   string
+		scopes []string
+		svc    string
+		want   bool
+	}{
+		{"empty scopes is unrestricted", nil, "mcp-service", true},
+		{"exact match", []string{"mcp-service"}, "mcp-service", true},
+		{"wildcard", []string{"*"}, "rtpi-pen", true},
+		{"no match", []string{"tools-service"}, "mcp-service", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := scopeAllows(tt.scopes, tt.svc); got != tt.want {
+				t.Errorf("scopeAllows(%v, %q) = %v, want %v", tt.scopes, tt.svc, got, tt.want)
+			}
+		})
+	}
+}
+
+func newTestContext(method string, header http.Header) (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	req := httptest.NewRequest(method, "/proxy/mcp-service/status", nil)
+	for k, values := range header {
+		for _, v := range values {
+			req.Header.Add(k, v)
+		}
+	}
+	c.Request = req
+	c.Params = gin.Params{{Key: "service", Value: "mcp-service"}}
+	return c, rec
+}
+
+func TestMiddlewareStripsForwardedHeadersOnPassthrough(t *testing.T) {
+	a := New(nil, nil, nil) // no rules at all: every service passes through
+
+	c, rec := newTestContext(http.MethodGet, http.Header{
+		"X-Forwarded-User":   {"attacker"},
+		"X-Forwarded-Scopes": {"*"},
+	})
+
+	a.Middleware()(c)
+
+	if rec.Code != 0 && rec.Code != http.StatusOK {
+		t.Fatalf("expected the request to pass through, got status %d", rec.Code)
+	}
+	if got := c.Request.Header.Get("X-Forwarded-User"); got != "" {
+		t.Errorf("expected X-Forwarded-User to be stripped, got %q", got)
+	}
+	if got := c.Request.Header.Get("X-Forwarded-Scopes"); got != "" {
+		t.Errorf("expected X-Forwarded-Scopes to be stripped, got %q", got)
+	}
+}
+
+func TestMiddlewareRejectsUnauthenticatedWhenRuleMatches(t *testing.T) {
+	keys, err := NewKeyStore("")
+	if err != nil {
+		t.Fatalf("NewKeyStore: %v", err)
+	}
+	a := New(keys, nil, []Rule{{Prefix: "mcp-service", Schemes: []string{SchemeAPIKey}}})
+
+	c, rec := newTestContext(http.MethodGet, nil)
+	a.Middleware()(c)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", rec.Code)
+	}
+	if !c.IsAborted() {
+		t.Error("expected the middleware to abort the request chain")
+	}
+}
+
+func TestMiddlewareAcceptsValidAPIKeyAndSetsForwardedHeaders(t *testing.T) {
+	keys, err := NewKeyStore("")
+	if err != nil {
+		t.Fatalf("NewKeyStore: %v", err)
+	}
+	key, secret, err := keys.Create("alice", []string{"mcp-service"})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	a := New(keys, nil, []Rule{{Prefix: "mcp-service", Schemes: []string{SchemeAPIKey}}})
+
+	c, rec := newTestContext(http.MethodGet, http.Header{
+		"Authorization": {"Bearer " + secret},
+	})
+	a.Middleware()(c)
+
+	if c.IsAborted() {
+		t.Fatalf("expected the request to be authenticated, got status %d", rec.Code)
+	}
+	if got := c.Request.Header.Get("X-Forwarded-User"); got != key.Name {
+		t.Errorf("X-Forwarded-User = %q, want %q", got, key.Name)
+	}
+}
+
+func TestMiddlewareRejectsScopeMismatch(t *testing.T) {
+	keys, err := NewKeyStore("")
+	if err != nil {
+		t.Fatalf("NewKeyStore: %v", err)
+	}
+	_, secret, err := keys.Create("alice", []string{"tools-service"})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	a := New(keys, nil, []Rule{{Prefix: "mcp-service", Schemes: []string{SchemeAPIKey}}})
+
+	c, rec := newTestContext(http.MethodGet, http.Header{
+		"Authorization": {"Bearer " + secret},
+	})
+	a.Middleware()(c)
+
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 for an out-of-scope key, got %d", rec.Code)
+	}
+}
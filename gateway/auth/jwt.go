@@ -0,0 +1,246 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// jwk is one entry of a JWKS response, as published by an OIDC provider.
+// Only RSA signing keys (the common case for JWKS-backed auth) are
+// supported.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+type jwksResponse struct {
+	Keys []jwk `json:"keys"`
+}
+
+// JWTValidator verifies RS256-signed JWT bearer tokens against a JWKS
+// endpoint, caching the fetched keys for CacheTTL so a valid token doesn't
+// cost a round trip to the identity provider on every request.
+type JWTValidator struct {
+	JWKSURL  string
+	Issuer   string
+	Audience string
+	CacheTTL time.Duration
+
+	httpClient *http.Client
+
+	mu      sync.Mutex
+	fetched time.Time
+	keys    map[string]*rsa.PublicKey
+}
+
+// NewJWTValidator builds a validator for the given JWKS URL. A zero
+// cacheTTL defaults to five minutes.
+func NewJWTValidator(jwksURL, issuer, audience string, cacheTTL time.Duration) *JWTValidator {
+	if cacheTTL <= 0 {
+		cacheTTL = 5 * time.Minute
+	}
+	return &JWTValidator{
+		JWKSURL:    jwksURL,
+		Issuer:     issuer,
+		Audience:   audience,
+		CacheTTL:   cacheTTL,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Claims is the subset of a JWT's payload the gateway cares about.
+type Claims struct {
+	Subject string
+	Scopes  []string
+}
+
+// Validate verifies tokenString's signature, issuer, audience, and
+// expiry, returning the caller's subject and scopes on success.
+func (v *JWTValidator) Validate(ctx context.Context, tokenString string) (*Claims, error) {
+	parts := strings.Split(tokenString, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("malformed jwt")
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	headerJSON, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("decoding jwt header: %w", err)
+	}
+	if err := json.Unmarshal(headerJSON, &header); err != nil {
+		return nil, fmt.Errorf("parsing jwt header: %w", err)
+	}
+	if header.Alg != "RS256" {
+		return nil, fmt.Errorf("unsupported jwt alg %q", header.Alg)
+	}
+
+	key, err := v.key(ctx, header.Kid)
+	if err != nil {
+		return nil, fmt.Errorf("resolving signing key: %w", err)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("decoding jwt signature: %w", err)
+	}
+	signed := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, signed[:], signature); err != nil {
+		return nil, fmt.Errorf("invalid jwt signature: %w", err)
+	}
+
+	var claims struct {
+		Subject  string   `json:"sub"`
+		Issuer   string   `json:"iss"`
+		Audience any      `json:"aud"`
+		Expiry   int64    `json:"exp"`
+		NotBefor int64    `json:"nbf"`
+		Scope    string   `json:"scope"`
+		Scopes   []string `json:"scopes"`
+	}
+	payloadJSON, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("decoding jwt payload: %w", err)
+	}
+	if err := json.Unmarshal(payloadJSON, &claims); err != nil {
+		return nil, fmt.Errorf("parsing jwt payload: %w", err)
+	}
+
+	now := time.Now().Unix()
+	if claims.Expiry != 0 && now >= claims.Expiry {
+		return nil, fmt.Errorf("jwt expired")
+	}
+	if claims.NotBefor != 0 && now < claims.NotBefor {
+		return nil, fmt.Errorf("jwt not yet valid")
+	}
+	if v.Issuer != "" && claims.Issuer != v.Issuer {
+		return nil, fmt.Errorf("unexpected issuer %q", claims.Issuer)
+	}
+	if v.Audience != "" && !audienceContains(claims.Audience, v.Audience) {
+		return nil, fmt.Errorf("token not valid for this audience")
+	}
+
+	scopes := claims.Scopes
+	if len(scopes) == 0 && claims.Scope != "" {
+		scopes = strings.Fields(claims.Scope)
+	}
+	return &Claims{Subject: claims.Subject, Scopes: scopes}, nil
+}
+
+// audienceContains reports whether aud (either a single string or a JSON
+// array of strings, per RFC 7519) contains want.
+func audienceContains(aud any, want string) bool {
+	switch v := aud.(type) {
+	case string:
+		return v == want
+	case []any:
+		for _, entry := range v {
+			if s, ok := entry.(string); ok && s == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// key returns the RSA public key for kid, fetching (or refreshing) the
+// JWKS document if it's stale or the kid is unknown.
+func (v *JWTValidator) key(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	v.mu.Lock()
+	stale := time.Since(v.fetched) > v.CacheTTL
+	key, known := v.keys[kid]
+	v.mu.Unlock()
+
+	if known && !stale {
+		return key, nil
+	}
+
+	if err := v.refresh(ctx); err != nil {
+		if known {
+			return key, nil
+		}
+		return nil, err
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	key, known = v.keys[kid]
+	if !known {
+		return nil, fmt.Errorf("no jwks key for kid %q", kid)
+	}
+	return key, nil
+}
+
+// refresh re-fetches and re-parses the JWKS document.
+func (v *JWTValidator) refresh(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.JWKSURL, nil)
+	if err != nil {
+		return fmt.Errorf("building jwks request: %w", err)
+	}
+	resp, err := v.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("fetching jwks: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("jwks endpoint returned status %d", resp.StatusCode)
+	}
+
+	var parsed jwksResponse
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return fmt.Errorf("parsing jwks response: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(parsed.Keys))
+	for _, k := range parsed.Keys {
+		if k.Kty != "RSA" || k.Kid == "" {
+			continue
+		}
+		pub, err := rsaPublicKey(k.N, k.E)
+		if err != nil {
+			continue
+		}
+		keys[k.Kid] = pub
+	}
+
+	v.mu.Lock()
+	v.keys = keys
+	v.fetched = time.Now()
+	v.mu.Unlock()
+	return nil
+}
+
+// rsaPublicKey builds an *rsa.PublicKey from a JWK's base64url-encoded
+// modulus and exponent.
+func rsaPublicKey(n, e string) (*rsa.PublicKey, error) {
+	nBytes, err := base64.RawURLEncoding.DecodeString(n)
+	if err != nil {
+		return nil, fmt.Errorf("decoding modulus: %w", err)
+	}
+	eBytes, err := base64.RawURLEncoding.DecodeString(e)
+	if err != nil {
+		return nil, fmt.Errorf("decoding exponent: %w", err)
+	}
+
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(nBytes),
+		E: int(new(big.Int).SetBytes(eBytes).Int64()),
+	}, nil
+}
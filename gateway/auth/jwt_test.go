@@ -0,0 +1,182 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestAudienceContains(t *testing.T) {
+	tests := []struct {
+		name string
+		aud  any
+		want string
+		ok   bool
+	}{
+		{"single string match", "gateway", "gateway", true},
+		{"single string mismatch", "other", "gateway", false},
+		{"array match", []any{"a", "gateway"}, "gateway", true},
+		{"array mismatch", []any{"a", "b"}, "gateway", false},
+		{"unsupported type", 42, "gateway", false},
+		{"nil", nil, "gateway", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := audienceContains(tt.aud, tt.want); got != tt.ok {
+				t.Errorf("audienceContains(%v, %q) = %v, want %v", tt.aud, tt.want, got, tt.ok)
+			}
+		})
+	}
+}
+
+// signedToken builds an RS256 JWT for claims, signed with key, with the
+// given kid in its header — enough to exercise JWTValidator.Validate
+// without standing up a JWKS endpoint.
+func signedToken(t *testing.T, key *rsa.PrivateKey, kid string, claims map[string]any) string {
+	t.Helper()
+
+	header := map[string]any{"alg": "RS256", "kid": kid}
+	headerJSON, err := json.Marshal(header)
+	if err != nil {
+		t.Fatalf("marshal header: %v", err)
+	}
+	claimsJSON, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatalf("marshal claims: %v", err)
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(headerJSON) + "." + base64.RawURLEncoding.EncodeToString(claimsJSON)
+	hashed := sha256.Sum256([]byte(signingInput))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, hashed[:])
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+// newTestValidator builds a JWTValidator with its key cache pre-seeded, so
+// Validate never needs to fetch a JWKS document over the network.
+func newTestValidator(issuer, audience string, kid string, pub *rsa.PublicKey) *JWTValidator {
+	return &JWTValidator{
+		Issuer:     issuer,
+		Audience:   audience,
+		CacheTTL:   time.Hour,
+		httpClient: &http.Client{Timeout: time.Second},
+		fetched:    time.Now(),
+		keys:       map[string]*rsa.PublicKey{kid: pub},
+	}
+}
+
+func TestJWTValidateAcceptsWellFormedToken(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	v := newTestValidator("gateway-issuer", "gateway", "kid1", &key.PublicKey)
+
+	token := signedToken(t, key, "kid1", map[string]any{
+		"sub": "alice", "iss": "gateway-issuer", "aud": "gateway",
+		"exp": time.Now().Add(time.Hour).Unix(), "scopes": []string{"mcp-service"},
+	})
+
+	claims, err := v.Validate(context.Background(), token)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if claims.Subject != "alice" || len(claims.Scopes) != 1 || claims.Scopes[0] != "mcp-service" {
+		t.Errorf("unexpected claims: %+v", claims)
+	}
+}
+
+func TestJWTValidateRejectsExpiredToken(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	v := newTestValidator("", "", "kid1", &key.PublicKey)
+
+	token := signedToken(t, key, "kid1", map[string]any{
+		"sub": "alice", "exp": time.Now().Add(-time.Minute).Unix(),
+	})
+
+	if _, err := v.Validate(context.Background(), token); err == nil {
+		t.Fatal("expected an error for an expired token")
+	}
+}
+
+func TestJWTValidateRejectsWrongIssuer(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	v := newTestValidator("expected-issuer", "", "kid1", &key.PublicKey)
+
+	token := signedToken(t, key, "kid1", map[string]any{
+		"sub": "alice", "iss": "other-issuer", "exp": time.Now().Add(time.Hour).Unix(),
+	})
+
+	if _, err := v.Validate(context.Background(), token); err == nil {
+		t.Fatal("expected an error for a mismatched issuer")
+	}
+}
+
+func TestJWTValidateRejectsWrongAudience(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	v := newTestValidator("", "expected-aud", "kid1", &key.PublicKey)
+
+	token := signedToken(t, key, "kid1", map[string]any{
+		"sub": "alice", "aud": "other-aud", "exp": time.Now().Add(time.Hour).Unix(),
+	})
+
+	if _, err := v.Validate(context.Background(), token); err == nil {
+		t.Fatal("expected an error for a mismatched audience")
+	}
+}
+
+func TestJWTValidateRejectsBadSignature(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	// Validator trusts "other"'s public key, but the token is signed by key.
+	v := newTestValidator("", "", "kid1", &other.PublicKey)
+
+	token := signedToken(t, key, "kid1", map[string]any{
+		"sub": "alice", "exp": time.Now().Add(time.Hour).Unix(),
+	})
+
+	if _, err := v.Validate(context.Background(), token); err == nil {
+		t.Fatal("expected an error for a signature that doesn't match the trusted key")
+	}
+}
+
+func TestJWTValidateRejectsUnknownKid(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	v := newTestValidator("", "", "kid1", &key.PublicKey)
+
+	token := signedToken(t, key, "kid-unknown", map[string]any{
+		"sub": "alice", "exp": time.Now().Add(time.Hour).Unix(),
+	})
+
+	if _, err := v.Validate(context.Background(), token); err == nil {
+		t.Fatal("expected an error for an unrecognized kid")
+	}
+}
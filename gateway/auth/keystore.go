@@ -0,0 +1,249 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// APIKey is one static credential accepted by the gateway. Secret is never
+// stored; only its bcrypt hash is, so the store file is safe at rest.
+type APIKey struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Hash      string    `json:"hash"`
+	Scopes    []string  `json:"scopes,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// KeyStore holds the gateway's static API keys, persisted to a JSON file so
+// rotations survive a restart. Presented keys have the form "<id>.<secret>":
+// the id looks up the hash in O(1), and only the secret half is bcrypt-
+// compared.
+type KeyStore struct {
+	mu   sync.RWMutex
+	path string
+	keys map[string]*APIKey
+}
+
+// NewKeyStore loads path, or starts empty if it doesn't exist yet.
+func NewKeyStore(path string) (*KeyStore, error) {
+	keys, err := loadKeys(path)
+	if err != nil {
+		return nil, err
+	}
+	return &KeyStore{path: path, keys: keys}, nil
+}
+
+// Create mints a new key with the given name and scopes, returning the
+// caller-visible secret exactly once; only its hash is retained.
+func (s *KeyStore) Create(name string, scopes []string) (*APIKey, string, error) {
+	id, err := randomToken(8)
+	if err != nil {
+		return nil, "", fmt.Errorf("generating key id: %w", err)
+	}
+	secret, err := randomToken(24)
+	if err != nil {
+		return nil, "", fmt.Errorf("generating key secret: %w", err)
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(secret), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, "", fmt.Errorf("hashing key secret: %w", err)
+	}
+
+	key := &APIKey{
+		ID:        id,
+		Name:      name,
+		Hash:      string(hash),
+		Scopes:    scopes,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys[id] = key
+	if err := s.persistLocked(); err != nil {
+		delete(s.keys, id)
+		return nil, "", err
+	}
+	redacted := *key
+	redacted.Hash = ""
+	return &redacted, fmt.Sprintf("%s.%s", id, secret), nil
+}
+
+// Rotate replaces the secret for an existing key id, keeping its name and
+// scopes, and returns the new caller-visible value.
+func (s *KeyStore) Rotate(id string) (string, error) {
+	secret, err := randomToken(24)
+	if err != nil {
+		return "", fmt.Errorf("generating key secret: %w", err)
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(secret), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("hashing key secret: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key, exists := s.keys[id]
+	if !exists {
+		return "", fmt.Errorf("key %s not found", id)
+	}
+	previousHash := key.Hash
+	key.Hash = string(hash)
+	if err := s.persistLocked(); err != nil {
+		key.Hash = previousHash
+		return "", err
+	}
+	return fmt.Sprintf("%s.%s", id, secret), nil
+}
+
+// Delete removes a key so it can no longer authenticate.
+func (s *KeyStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.keys[id]; !exists {
+		return fmt.Errorf("key %s not found", id)
+	}
+	delete(s.keys, id)
+	return s.persistLocked()
+}
+
+// Path returns the file currently backing the store.
+func (s *KeyStore) Path() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.path
+}
+
+// Reopen switches the store to read and persist at a new path, replacing
+// the in-memory key table with whatever is there (or starting empty if
+// path has never been written to). Used when the config's
+// auth.key_store_path changes across a reload, so admin routes set up
+// against the original *KeyStore see the new backing file too.
+func (s *KeyStore) Reopen(path string) error {
+	keys, err := loadKeys(path)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.path = path
+	s.keys = keys
+	return nil
+}
+
+// List returns every key's metadata, without secrets or hashes.
+func (s *KeyStore) List() []*APIKey {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	keys := make([]*APIKey, 0, len(s.keys))
+	for _, key := range s.keys {
+		redacted := *key
+		redacted.Hash = ""
+		keys = append(keys, &redacted)
+	}
+	return keys
+}
+
+// Authenticate checks a presented "<id>.<secret>" key and returns the
+// matching key's metadata on success.
+func (s *KeyStore) Authenticate(presented string) (*APIKey, bool) {
+	id, secret, ok := strings.Cut(presented, ".")
+	if !ok {
+		return nil, false
+	}
+
+	s.mu.RLock()
+	key, exists := s.keys[id]
+	s.mu.RUnlock()
+	if !exists {
+		return nil, false
+	}
+
+	if bcrypt.CompareHashAndPassword([]byte(key.Hash), []byte(secret)) != nil {
+		return nil, false
+	}
+	redacted := *key
+	redacted.Hash = ""
+	return &redacted, true
+}
+
+// persistLocked writes the key table to disk. Callers must hold s.mu.
+func (s *KeyStore) persistLocked() error {
+	if s.path == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(s.keys, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling key store: %w", err)
+	}
+
+	dir := filepath.Dir(s.path)
+	tmp, err := os.CreateTemp(dir, ".keys-*.tmp")
+	if err != nil {
+		return fmt.Errorf("creating temp key store file: %w", err)
+	}
+	tmpPath := tmp.Name()
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("writing temp key store file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("closing temp key store file: %w", err)
+	}
+	if err := os.Chmod(tmpPath, 0600); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("setting key store file permissions: %w", err)
+	}
+	if err := os.Rename(tmpPath, s.path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("replacing key store file: %w", err)
+	}
+	return nil
+}
+
+// loadKeys reads a previously persisted key table from path. A missing file
+// is not an error; it just means no keys have been issued yet.
+func loadKeys(path string) (map[string]*APIKey, error) {
+	if path == "" {
+		return map[string]*APIKey{}, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]*APIKey{}, nil
+		}
+		return nil, fmt.Errorf("reading key store file: %w", err)
+	}
+
+	var keys map[string]*APIKey
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return nil, fmt.Errorf("parsing key store file: %w", err)
+	}
+	return keys, nil
+}
+
+// randomToken returns a URL-safe random token encoding n bytes of entropy.
+func randomToken(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"log"
+	"sync/atomic"
+	"time"
+
+	"github.com/cmndcntrlcyber/offsec-team/gateway/auth"
+	gwconfig "github.com/cmndcntrlcyber/offsec-team/gateway/config"
+	"github.com/gin-gonic/gin"
+)
+
+// authHolder lets /admin/reload swap the active auth rules and JWKS
+// settings without restarting the server: each proxied request loads
+// whatever *auth.Authenticator is current at the moment it arrives. The
+// underlying *auth.KeyStore is a single long-lived object, reopened in
+// place if auth.key_store_path changes across a reload, so the
+// /admin/keys routes (wired up once against this same object) keep
+// reading and writing wherever the current config points.
+type authHolder struct {
+	keys    *auth.KeyStore
+	current atomic.Value // *auth.Authenticator
+}
+
+// newAuthHolder opens the key store at cfg's configured path (if any) and
+// builds the initial Authenticator. A nil cfg leaves every route
+// unauthenticated.
+func newAuthHolder(cfg *gwconfig.Auth) (*authHolder, error) {
+	var keyStorePath string
+	if cfg != nil {
+		keyStorePath = cfg.KeyStorePath
+	}
+	keys, err := auth.NewKeyStore(keyStorePath)
+	if err != nil {
+		return nil, err
+	}
+
+	h := &authHolder{keys: keys}
+	h.set(cfg)
+	return h, nil
+}
+
+// set rebuilds the active Authenticator from cfg, reopening the key store
+// in place if its configured path has changed.
+func (h *authHolder) set(cfg *gwconfig.Auth) {
+	var keyStorePath string
+	if cfg != nil {
+		keyStorePath = cfg.KeyStorePath
+	}
+	if keyStorePath != h.keys.Path() {
+		if err := h.keys.Reopen(keyStorePath); err != nil {
+			log.Printf("warning: failed to reopen auth key store at %q, keeping previous keys: %v", keyStorePath, err)
+		}
+	}
+
+	if cfg == nil {
+		h.current.Store(auth.New(h.keys, nil, nil))
+		return
+	}
+
+	var jwt *auth.JWTValidator
+	if cfg.JWKSURL != "" {
+		jwt = auth.NewJWTValidator(cfg.JWKSURL, cfg.Issuer, cfg.Audience, time.Duration(cfg.JWKSCacheSecs)*time.Second)
+	}
+
+	rules := make([]auth.Rule, len(cfg.Rules))
+	for i, rule := range cfg.Rules {
+		rules[i] = auth.Rule{Prefix: rule.Prefix, Schemes: rule.Schemes}
+	}
+
+	h.current.Store(auth.New(h.keys, jwt, rules))
+}
+
+func (h *authHolder) middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		h.current.Load().(*auth.Authenticator).Middleware()(c)
+	}
+}
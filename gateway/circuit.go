@@ -0,0 +1,100 @@
+package main
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// circuitState is the state of a per-service circuit breaker.
+type circuitState int
+
+const (
+	circuitClosed circuitState = iota
+	circuitOpen
+	circuitHalfOpen
+)
+
+func (s circuitState) String() string {
+	switch s {
+	case circuitOpen:
+		return "open"
+	case circuitHalfOpen:
+		return "half_open"
+	default:
+		return "closed"
+	}
+}
+
+func (s circuitState) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.String())
+}
+
+const (
+	// circuitFailureThreshold is the number of consecutive 5xx responses or
+	// connect errors that trip a closed breaker open.
+	circuitFailureThreshold = 5
+	// circuitCooldown is how long a breaker stays open before allowing a
+	// single half-open probe request through.
+	circuitCooldown = 30 * time.Second
+)
+
+// allowRequest reports whether a request to name may proceed given its
+// current circuit state, transitioning open -> half-open once the cooldown
+// has elapsed. It returns the remaining cooldown when the request must be
+// rejected.
+func (g *Gateway) allowRequest(name string) (bool, time.Duration) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	service, exists := g.services[name]
+	if !exists {
+		return false, 0
+	}
+
+	switch service.Circuit {
+	case circuitOpen:
+		remaining := circuitCooldown - time.Since(service.circuitOpenedAt)
+		if remaining > 0 {
+			return false, remaining
+		}
+		service.Circuit = circuitHalfOpen
+		return true, 0
+	case circuitHalfOpen:
+		// Only one probe is allowed in flight at a time.
+		return false, 0
+	default:
+		return true, 0
+	}
+}
+
+// recordResult updates a service's circuit breaker and failure counters
+// after an upstream attempt. success is false for connect errors and 5xx
+// responses.
+func (g *Gateway) recordResult(name string, success bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	service, exists := g.services[name]
+	if !exists {
+		return
+	}
+
+	if success {
+		service.FailureCount = 0
+		service.Circuit = circuitClosed
+		return
+	}
+
+	service.FailureCount++
+
+	switch service.Circuit {
+	case circuitHalfOpen:
+		service.Circuit = circuitOpen
+		service.circuitOpenedAt = time.Now()
+	case circuitClosed:
+		if service.FailureCount >= circuitFailureThreshold {
+			service.Circuit = circuitOpen
+			service.circuitOpenedAt = time.Now()
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import "testing"
+
+func newTestGateway(name string) *Gateway {
+	return &Gateway{
+		services: map[string]*Service{
+			name: {Name: name},
+		},
+	}
+}
+
+func TestCircuitClosedAllowsRequests(t *testing.T) {
+	g := newTestGateway("svc")
+
+	allowed, _ := g.allowRequest("svc")
+	if !allowed {
+		t.Fatal("expected a closed breaker to allow the request")
+	}
+}
+
+func TestCircuitTripsAfterThreshold(t *testing.T) {
+	g := newTestGateway("svc")
+
+	for i := 0; i < circuitFailureThreshold-1; i++ {
+		g.recordResult("svc", false)
+		if allowed, _ := g.allowRequest("svc"); !allowed {
+			t.Fatalf("breaker tripped early after %d failures", i+1)
+		}
+	}
+
+	g.recordResult("svc", false)
+	if allowed, remaining := g.allowRequest("svc"); allowed || remaining <= 0 {
+		t.Fatalf("expected breaker open with a positive cooldown after %d failures, got allowed=%v remaining=%v", circuitFailureThreshold, allowed, remaining)
+	}
+}
+
+func TestCircuitSuccessResetsFailureCount(t *testing.T) {
+	g := newTestGateway("svc")
+
+	for i := 0; i < circuitFailureThreshold-1; i++ {
+		g.recordResult("svc", false)
+	}
+	g.recordResult("svc", true)
+
+	g.mu.RLock()
+	failures := g.services["svc"].FailureCount
+	state := g.services["svc"].Circuit
+	g.mu.RUnlock()
+
+	if failures != 0 || state != circuitClosed {
+		t.Fatalf("expected a success to reset failures and keep the breaker closed, got failures=%d state=%v", failures, state)
+	}
+}
+
+func TestCircuitHalfOpenAllowsOneProbe(t *testing.T) {
+	g := newTestGateway("svc")
+
+	for i := 0; i < circuitFailureThreshold; i++ {
+		g.recordResult("svc", false)
+	}
+
+	g.mu.Lock()
+	g.services["svc"].circuitOpenedAt = g.services["svc"].circuitOpenedAt.Add(-circuitCooldown)
+	g.mu.Unlock()
+
+	probeAllowed, _ := g.allowRequest("svc")
+	if !probeAllowed {
+		t.Fatal("expected the breaker to allow a single probe once the cooldown elapsed")
+	}
+
+	g.mu.RLock()
+	state := g.services["svc"].Circuit
+	g.mu.RUnlock()
+	if state != circuitHalfOpen {
+		t.Fatalf("expected breaker to move to half-open after the cooldown, got %v", state)
+	}
+
+	if secondAllowed, _ := g.allowRequest("svc"); secondAllowed {
+		t.Fatal("expected only one probe to be allowed in flight while half-open")
+	}
+}
+
+func TestCircuitHalfOpenFailureReopens(t *testing.T) {
+	g := newTestGateway("svc")
+	g.services["svc"].Circuit = circuitHalfOpen
+
+	g.recordResult("svc", false)
+
+	g.mu.RLock()
+	state := g.services["svc"].Circuit
+	g.mu.RUnlock()
+	if state != circuitOpen {
+		t.Fatalf("expected a half-open probe failure to reopen the breaker, got %v", state)
+	}
+}
+
+func TestCircuitHalfOpenSuccessCloses(t *testing.T) {
+	g := newTestGateway("svc")
+	g.services["svc"].Circuit = circuitHalfOpen
+	g.services["svc"].FailureCount = circuitFailureThreshold
+
+	g.recordResult("svc", true)
+
+	g.mu.RLock()
+	state := g.services["svc"].Circuit
+	failures := g.services["svc"].FailureCount
+	g.mu.RUnlock()
+	if state != circuitClosed || failures != 0 {
+		t.Fatalf("expected a half-open probe success to close the breaker and reset failures, got state=%v failures=%d", state, failures)
+	}
+}
+
+func TestAllowRequestUnknownService(t *testing.T) {
+	g := newTestGateway("svc")
+
+	if allowed, _ := g.allowRequest("missing"); allowed {
+		t.Fatal("expected an unregistered service to not be allowed")
+	}
+}
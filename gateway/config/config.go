@@ -0,0 +1,253 @@
+// Package config loads and validates the gateway's declarative service
+// topology from a YAML or JSON file, replacing the hardcoded service list
+// that used to live in main().
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Service describes one backend the gateway proxies to.
+type Service struct {
+	Name       string   `yaml:"name" json:"name"`
+	URL        string   `yaml:"url" json:"url"`
+	HealthPath string   `yaml:"health_path" json:"health_path"`
+	Endpoints  []string `yaml:"endpoints" json:"endpoints"`
+	Tags       []string `yaml:"tags" json:"tags"`
+	TimeoutMs  int      `yaml:"timeout_ms" json:"timeout_ms"`
+	MaxRetries int      `yaml:"max_retries" json:"max_retries"`
+	BackoffMs  int      `yaml:"backoff_ms" json:"backoff_ms"`
+}
+
+// Config is the top-level shape of gateway.yaml (or .json).
+type Config struct {
+	Port        string    `yaml:"port" json:"port"`
+	CORSOrigins []string  `yaml:"cors_origins" json:"cors_origins"`
+	Services    []Service `yaml:"services" json:"services"`
+	Auth        *Auth     `yaml:"auth,omitempty" json:"auth,omitempty"`
+}
+
+// Auth declares the gateway's pluggable authentication: where static API
+// keys are persisted, how to validate JWTs against a JWKS endpoint, and
+// which scheme(s) each /proxy/:service route prefix requires. A nil Auth
+// leaves every route unauthenticated, matching the gateway's historical
+// behavior.
+type Auth struct {
+	KeyStorePath  string     `yaml:"key_store_path" json:"key_store_path"`
+	JWKSURL       string     `yaml:"jwks_url" json:"jwks_url"`
+	Issuer        string     `yaml:"issuer" json:"issuer"`
+	Audience      string     `yaml:"audience" json:"audience"`
+	JWKSCacheSecs int        `yaml:"jwks_cache_seconds" json:"jwks_cache_seconds"`
+	Rules         []AuthRule `yaml:"rules" json:"rules"`
+}
+
+// AuthRule requires one of Schemes for any /proxy/:service request whose
+// service name matches Prefix. "*" matches every service not covered by a
+// more specific rule.
+type AuthRule struct {
+	Prefix  string   `yaml:"prefix" json:"prefix"`
+	Schemes []string `yaml:"schemes" json:"schemes"`
+}
+
+// Scheme names accepted in an AuthRule.
+const (
+	SchemeAPIKey = "api_key"
+	SchemeJWT    = "jwt"
+)
+
+// Load reads and validates a Config from path. The format is chosen by the
+// file extension: ".json" is parsed as JSON, anything else as YAML.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading config file: %w", err)
+	}
+
+	var cfg Config
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		if err := json.Unmarshal(data, &cfg); err != nil {
+			return nil, fmt.Errorf("parsing config file as JSON: %w", err)
+		}
+	} else {
+		if err := yaml.Unmarshal(data, &cfg); err != nil {
+			return nil, fmt.Errorf("parsing config file as YAML: %w", err)
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config file: %w", err)
+	}
+	return &cfg, nil
+}
+
+// Upper bounds on a service's per-request proxy policy. A value above
+// these is rejected rather than silently honored, since an unbounded
+// max_retries turns one inbound request into an unbounded number of
+// upstream round trips.
+const (
+	MaxTimeoutMs = 120000
+	MaxRetries   = 10
+	MaxBackoffMs = 10000
+)
+
+// Validate checks that service names are unique, URLs are well-formed, no
+// two services would claim the same /proxy route prefix, and that each
+// service's proxy policy is within bounds. Route prefixes are derived from
+// service names, so the name-uniqueness check also covers prefix overlap.
+func (c *Config) Validate() error {
+	seen := make(map[string]bool, len(c.Services))
+	for _, svc := range c.Services {
+		if svc.Name == "" {
+			return fmt.Errorf("service entry missing name")
+		}
+		if seen[svc.Name] {
+			return fmt.Errorf("duplicate service name %q", svc.Name)
+		}
+		seen[svc.Name] = true
+
+		parsed, err := url.Parse(svc.URL)
+		if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+			return fmt.Errorf("service %q has an invalid url %q", svc.Name, svc.URL)
+		}
+
+		if svc.TimeoutMs > MaxTimeoutMs {
+			return fmt.Errorf("service %q timeout_ms %d exceeds the limit of %d", svc.Name, svc.TimeoutMs, MaxTimeoutMs)
+		}
+		if svc.MaxRetries > MaxRetries {
+			return fmt.Errorf("service %q max_retries %d exceeds the limit of %d", svc.Name, svc.MaxRetries, MaxRetries)
+		}
+		if svc.BackoffMs > MaxBackoffMs {
+			return fmt.Errorf("service %q backoff_ms %d exceeds the limit of %d", svc.Name, svc.BackoffMs, MaxBackoffMs)
+		}
+	}
+
+	if c.Auth != nil {
+		if err := c.Auth.validate(); err != nil {
+			return fmt.Errorf("auth: %w", err)
+		}
+	}
+	return nil
+}
+
+// validate checks that every rule names a known scheme and that a jwt rule
+// isn't declared without the JWKS settings it needs.
+func (a *Auth) validate() error {
+	needsJWKS := false
+	seenPrefix := make(map[string]bool, len(a.Rules))
+	for _, rule := range a.Rules {
+		if rule.Prefix == "" {
+			return fmt.Errorf("rule missing prefix")
+		}
+		if seenPrefix[rule.Prefix] {
+			return fmt.Errorf("duplicate rule prefix %q", rule.Prefix)
+		}
+		seenPrefix[rule.Prefix] = true
+
+		if len(rule.Schemes) == 0 {
+			return fmt.Errorf("rule %q lists no schemes", rule.Prefix)
+		}
+		for _, scheme := range rule.Schemes {
+			switch scheme {
+			case SchemeAPIKey:
+			case SchemeJWT:
+				needsJWKS = true
+			default:
+				return fmt.Errorf("rule %q names unknown scheme %q", rule.Prefix, scheme)
+			}
+		}
+	}
+	if needsJWKS && a.JWKSURL == "" {
+		return fmt.Errorf("jwt scheme requires jwks_url")
+	}
+	return nil
+}
+
+// Default returns the gateway's built-in service topology, used to seed a
+// starter gateway.yaml when none exists yet. mcp-service and rtpi-pen are
+// protected by a default api_key rule out of the box, since they expose
+// internal tooling and infrastructure control respectively; the other
+// services stay unauthenticated until an operator opts them in, matching
+// the gateway's pre-auth behavior.
+func Default() *Config {
+	return &Config{
+		Port:        "8005",
+		CORSOrigins: DefaultCORSOrigins(),
+		Auth: &Auth{
+			KeyStorePath: "keys.json",
+			Rules: []AuthRule{
+				{Prefix: "mcp-service", Schemes: []string{SchemeAPIKey}},
+				{Prefix: "rtpi-pen", Schemes: []string{SchemeAPIKey}},
+			},
+		},
+		Services: []Service{
+			{
+				Name:      "chat-service",
+				URL:       "http://chat-service:8080",
+				Endpoints: []string{"/health", "/openapi.json", "/execute", "/execute/contextual", "/researcher/callback"},
+			},
+			{
+				Name:      "tools-service",
+				URL:       "http://tools-service:8001",
+				Endpoints: []string{"/health", "/agents", "/execute", "/openapi.json"},
+			},
+			{
+				Name:      "research-service",
+				URL:       "http://research-service:8002",
+				Endpoints: []string{"/health", "/api/research/status", "/api/research/search", "/api/research/analyze"},
+			},
+			{
+				Name:      "mcp-service",
+				URL:       "http://mcp-service:8003",
+				Endpoints: []string{"/health", "/api/mcp/status", "/api/mcp/context", "/api/mcp/models"},
+			},
+			{
+				Name:      "rtpi-pen",
+				URL:       "http://rtpi-pen:8080",
+				Endpoints: []string{"/health", "/api/rtpi", "/status"},
+			},
+		},
+	}
+}
+
+// DefaultCORSOrigins returns the gateway's built-in allowed origins.
+func DefaultCORSOrigins() []string {
+	return []string{
+		"https://chat.attck.nexus",
+		"https://tools.attck.nexus",
+		"https://researcher.c3s.nexus",
+		"https://mcp.c3s.nexus",
+		"https://rtpi.attck.nexus",
+		"http://localhost:3001",
+		"http://localhost:8001",
+		"http://localhost:8002",
+		"http://localhost:8003",
+		"http://localhost:8004",
+		"http://localhost:8005",
+	}
+}
+
+// WriteStarter writes cfg to path as YAML if no file exists there yet. It
+// is the one-time migration off the hardcoded service list.
+func WriteStarter(path string, cfg *Config) error {
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("checking for existing config file: %w", err)
+	}
+
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return fmt.Errorf("marshaling starter config: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("writing starter config file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package config
+
+import "testing"
+
+func validConfig() *Config {
+	return &Config{
+		Services: []Service{
+			{Name: "svc-a", URL: "http://svc-a:8080"},
+			{Name: "svc-b", URL: "http://svc-b:8080"},
+		},
+	}
+}
+
+func TestValidateAcceptsWellFormedConfig(t *testing.T) {
+	if err := validConfig().Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestValidateRejectsMissingName(t *testing.T) {
+	cfg := &Config{Services: []Service{{URL: "http://svc:8080"}}}
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected an error for a service with no name")
+	}
+}
+
+func TestValidateRejectsDuplicateName(t *testing.T) {
+	cfg := &Config{Services: []Service{
+		{Name: "svc", URL: "http://a:8080"},
+		{Name: "svc", URL: "http://b:8080"},
+	}}
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected an error for a duplicate service name")
+	}
+}
+
+func TestValidateRejectsInvalidURL(t *testing.T) {
+	cfg := &Config{Services: []Service{{Name: "svc", URL: "not-a-url"}}}
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected an error for an invalid url")
+	}
+}
+
+func TestValidateRejectsPolicyAboveLimits(t *testing.T) {
+	tests := []struct {
+		name string
+		svc  Service
+	}{
+		{"timeout_ms too high", Service{Name: "svc", URL: "http://svc:8080", TimeoutMs: MaxTimeoutMs + 1}},
+		{"max_retries too high", Service{Name: "svc", URL: "http://svc:8080", MaxRetries: MaxRetries + 1}},
+		{"backoff_ms too high", Service{Name: "svc", URL: "http://svc:8080", BackoffMs: MaxBackoffMs + 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Services: []Service{tt.svc}}
+			if err := cfg.Validate(); err == nil {
+				t.Fatalf("expected an error, got none")
+			}
+		})
+	}
+}
+
+func TestAuthValidateRejectsUnknownScheme(t *testing.T) {
+	cfg := validConfig()
+	cfg.Auth = &Auth{Rules: []AuthRule{{Prefix: "svc-a", Schemes: []string{"bogus"}}}}
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected an error for an unknown scheme")
+	}
+}
+
+func TestAuthValidateRejectsEmptyPrefix(t *testing.T) {
+	cfg := validConfig()
+	cfg.Auth = &Auth{Rules: []AuthRule{{Schemes: []string{SchemeAPIKey}}}}
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected an error for a rule with no prefix")
+	}
+}
+
+func TestAuthValidateRejectsDuplicatePrefix(t *testing.T) {
+	cfg := validConfig()
+	cfg.Auth = &Auth{Rules: []AuthRule{
+		{Prefix: "svc-a", Schemes: []string{SchemeAPIKey}},
+		{Prefix: "svc-a", Schemes: []string{SchemeAPIKey}},
+	}}
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected an error for a duplicate rule prefix")
+	}
+}
+
+func TestAuthValidateRequiresJWKSURLForJWTScheme(t *testing.T) {
+	cfg := validConfig()
+	cfg.Auth = &Auth{Rules: []AuthRule{{Prefix: "svc-a", Schemes: []string{SchemeJWT}}}}
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected an error when a jwt rule is declared without jwks_url")
+	}
+
+	cfg.Auth.JWKSURL = "https://issuer.example.com/.well-known/jwks.json"
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("unexpected error once jwks_url is set: %v", err)
+	}
+}
+
+func TestDefaultConfigValidates(t *testing.T) {
+	if err := Default().Validate(); err != nil {
+		t.Fatalf("Default() config must pass its own validation: %v", err)
+	}
+}
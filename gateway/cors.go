@@ -0,0 +1,36 @@
+package main
+
+import (
+	"sync/atomic"
+
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+)
+
+// corsHolder lets GET /admin/reload swap the allowed origins without
+// restarting the server or dropping requests that are already mid-flight:
+// each request loads whatever handler is current at the moment it arrives.
+type corsHolder struct {
+	current atomic.Value // gin.HandlerFunc
+}
+
+func newCORSHolder(origins []string) *corsHolder {
+	h := &corsHolder{}
+	h.set(origins)
+	return h
+}
+
+func (h *corsHolder) set(origins []string) {
+	cfg := cors.DefaultConfig()
+	cfg.AllowOrigins = origins
+	cfg.AllowMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"}
+	cfg.AllowHeaders = []string{"*"}
+	cfg.AllowCredentials = true
+	h.current.Store(cors.New(cfg))
+}
+
+func (h *corsHolder) middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		h.current.Load().(gin.HandlerFunc)(c)
+	}
+}
@@ -0,0 +1,368 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// filterFields lists the Service fields selectable in a filter expression.
+// Anything else is rejected as an unknown identifier.
+var filterFields = map[string]bool{
+	"Name":   true,
+	"URL":    true,
+	"Status": true,
+	"tags":   true,
+}
+
+// tokenKind identifies a lexical token in a filter expression.
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokString
+	tokAnd
+	tokOr
+	tokNot
+	tokEq
+	tokNeq
+	tokMatches
+	tokLParen
+	tokRParen
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+// filterLexer splits a filter expression into tokens.
+type filterLexer struct {
+	input string
+	pos   int
+}
+
+func newFilterLexer(input string) *filterLexer {
+	return &filterLexer{input: input}
+}
+
+func (l *filterLexer) peekByte() byte {
+	if l.pos >= len(l.input) {
+		return 0
+	}
+	return l.input[l.pos]
+}
+
+func (l *filterLexer) skipSpace() {
+	for l.pos < len(l.input) && (l.input[l.pos] == ' ' || l.input[l.pos] == '\t') {
+		l.pos++
+	}
+}
+
+func (l *filterLexer) next() (token, error) {
+	l.skipSpace()
+	if l.pos >= len(l.input) {
+		return token{kind: tokEOF}, nil
+	}
+
+	c := l.input[l.pos]
+
+	switch {
+	case c == '(':
+		l.pos++
+		return token{kind: tokLParen, text: "("}, nil
+	case c == ')':
+		l.pos++
+		return token{kind: tokRParen, text: ")"}, nil
+	case c == '"':
+		return l.readString()
+	case c == '=' && l.pos+1 < len(l.input) && l.input[l.pos+1] == '=':
+		l.pos += 2
+		return token{kind: tokEq, text: "=="}, nil
+	case c == '!' && l.pos+1 < len(l.input) && l.input[l.pos+1] == '=':
+		l.pos += 2
+		return token{kind: tokNeq, text: "!="}, nil
+	case isIdentStart(c):
+		return l.readIdent(), nil
+	default:
+		return token{}, fmt.Errorf("unexpected character %q at position %d", c, l.pos)
+	}
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isIdentPart(c byte) bool {
+	return isIdentStart(c) || (c >= '0' && c <= '9')
+}
+
+func (l *filterLexer) readIdent() token {
+	start := l.pos
+	for l.pos < len(l.input) && isIdentPart(l.input[l.pos]) {
+		l.pos++
+	}
+	word := l.input[start:l.pos]
+	switch strings.ToLower(word) {
+	case "and":
+		return token{kind: tokAnd, text: word}
+	case "or":
+		return token{kind: tokOr, text: word}
+	case "not":
+		return token{kind: tokNot, text: word}
+	case "matches":
+		return token{kind: tokMatches, text: word}
+	default:
+		return token{kind: tokIdent, text: word}
+	}
+}
+
+func (l *filterLexer) readString() (token, error) {
+	start := l.pos
+	l.pos++ // opening quote
+	var sb strings.Builder
+	for l.pos < len(l.input) {
+		c := l.input[l.pos]
+		if c == '"' {
+			l.pos++
+			return token{kind: tokString, text: sb.String()}, nil
+		}
+		if c == '\\' && l.pos+1 < len(l.input) {
+			l.pos++
+			sb.WriteByte(l.input[l.pos])
+			l.pos++
+			continue
+		}
+		sb.WriteByte(c)
+		l.pos++
+	}
+	return token{}, fmt.Errorf("unterminated string starting at position %d", start)
+}
+
+// filterExpr is a node in the parsed filter AST.
+type filterExpr interface {
+	eval(s *Service) (bool, error)
+}
+
+type andExpr struct{ left, right filterExpr }
+type orExpr struct{ left, right filterExpr }
+type notExpr struct{ inner filterExpr }
+
+type compareExpr struct {
+	field string
+	op    tokenKind
+	value string
+}
+
+func (e *andExpr) eval(s *Service) (bool, error) {
+	l, err := e.left.eval(s)
+	if err != nil || !l {
+		return false, err
+	}
+	return e.right.eval(s)
+}
+
+func (e *orExpr) eval(s *Service) (bool, error) {
+	l, err := e.left.eval(s)
+	if err != nil {
+		return false, err
+	}
+	if l {
+		return true, nil
+	}
+	return e.right.eval(s)
+}
+
+func (e *notExpr) eval(s *Service) (bool, error) {
+	v, err := e.inner.eval(s)
+	if err != nil {
+		return false, err
+	}
+	return !v, nil
+}
+
+func (e *compareExpr) eval(s *Service) (bool, error) {
+	switch e.field {
+	case "Name":
+		return compareScalar(e.op, s.Name, e.value)
+	case "URL":
+		return compareScalar(e.op, s.URL, e.value)
+	case "Status":
+		return compareScalar(e.op, s.Status, e.value)
+	case "tags":
+		for _, tag := range s.Tags {
+			ok, err := compareScalar(e.op, tag, e.value)
+			if err != nil {
+				return false, err
+			}
+			if ok {
+				return true, nil
+			}
+		}
+		return false, nil
+	default:
+		return false, fmt.Errorf("unknown field %q", e.field)
+	}
+}
+
+func compareScalar(op tokenKind, actual, value string) (bool, error) {
+	switch op {
+	case tokEq:
+		return actual == value, nil
+	case tokNeq:
+		return actual != value, nil
+	case tokMatches:
+		re, err := regexp.Compile(value)
+		if err != nil {
+			return false, fmt.Errorf("invalid regex %q: %w", value, err)
+		}
+		return re.MatchString(actual), nil
+	default:
+		return false, fmt.Errorf("unsupported operator")
+	}
+}
+
+// filterParser is a small recursive-descent parser for the filter
+// expression language used by GET /services?filter=...
+//
+// grammar:
+//
+//	expr    := term (OR term)*
+//	term    := factor (AND factor)*
+//	factor  := NOT factor | "(" expr ")" | comparison
+//	comparison := IDENT ("==" | "!=" | "matches") STRING
+type filterParser struct {
+	lexer *filterLexer
+	tok   token
+}
+
+func newFilterParser(input string) (*filterParser, error) {
+	p := &filterParser{lexer: newFilterLexer(input)}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (p *filterParser) advance() error {
+	t, err := p.lexer.next()
+	if err != nil {
+		return err
+	}
+	p.tok = t
+	return nil
+}
+
+func parseFilter(input string) (filterExpr, error) {
+	p, err := newFilterParser(input)
+	if err != nil {
+		return nil, err
+	}
+	expr, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokEOF {
+		return nil, fmt.Errorf("unexpected token %q", p.tok.text)
+	}
+	return expr, nil
+}
+
+func (p *filterParser) parseExpr() (filterExpr, error) {
+	left, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+	for p.tok.kind == tokOr {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		right, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		left = &orExpr{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseTerm() (filterExpr, error) {
+	left, err := p.parseFactor()
+	if err != nil {
+		return nil, err
+	}
+	for p.tok.kind == tokAnd {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		right, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+		left = &andExpr{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseFactor() (filterExpr, error) {
+	switch p.tok.kind {
+	case tokNot:
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		inner, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+		return &notExpr{inner: inner}, nil
+	case tokLParen:
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		expr, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		if p.tok.kind != tokRParen {
+			return nil, fmt.Errorf("expected closing parenthesis")
+		}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		return expr, nil
+	default:
+		return p.parseComparison()
+	}
+}
+
+func (p *filterParser) parseComparison() (filterExpr, error) {
+	if p.tok.kind != tokIdent {
+		return nil, fmt.Errorf("expected field name, got %q", p.tok.text)
+	}
+	field := p.tok.text
+	if !filterFields[field] {
+		return nil, fmt.Errorf("unknown identifier %q", field)
+	}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	if p.tok.kind != tokEq && p.tok.kind != tokNeq && p.tok.kind != tokMatches {
+		return nil, fmt.Errorf("expected \"==\", \"!=\" or \"matches\", got %q", p.tok.text)
+	}
+	op := p.tok.kind
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	if p.tok.kind != tokString {
+		return nil, fmt.Errorf("expected quoted string value, got %q", p.tok.text)
+	}
+	value := p.tok.text
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	return &compareExpr{field: field, op: op, value: value}, nil
+}
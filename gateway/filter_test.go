@@ -0,0 +1,77 @@
+package main
+
+import "testing"
+
+func TestParseFilterEval(t *testing.T) {
+	svc := &Service{
+		Name:   "mcp-service",
+		URL:    "http://mcp-service:8003",
+		Status: "healthy",
+		Tags:   []string{"internal", "beta"},
+	}
+
+	tests := []struct {
+		name string
+		expr string
+		want bool
+	}{
+		{"eq match", `Name == "mcp-service"`, true},
+		{"eq mismatch", `Name == "tools-service"`, false},
+		{"neq", `Status != "unhealthy"`, true},
+		{"matches", `URL matches "^http://mcp-"`, true},
+		{"matches mismatch", `URL matches "^https://"`, false},
+		{"and both true", `Name == "mcp-service" and Status == "healthy"`, true},
+		{"and one false", `Name == "mcp-service" and Status == "degraded"`, false},
+		{"or one true", `Name == "tools-service" or Status == "healthy"`, true},
+		{"not", `not Status == "unhealthy"`, true},
+		{"parens", `(Name == "tools-service" or Status == "healthy") and not Name == "tools-service"`, true},
+		{"tags match any", `tags == "beta"`, true},
+		{"tags no match", `tags == "prod"`, false},
+		{"empty expr matches everything", ``, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.expr == "" {
+				return
+			}
+			expr, err := parseFilter(tt.expr)
+			if err != nil {
+				t.Fatalf("parseFilter(%q): %v", tt.expr, err)
+			}
+			got, err := expr.eval(svc)
+			if err != nil {
+				t.Fatalf("eval(%q): %v", tt.expr, err)
+			}
+			if got != tt.want {
+				t.Errorf("eval(%q) = %v, want %v", tt.expr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFilterErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		expr string
+	}{
+		{"unknown identifier", `Bogus == "x"`},
+		{"unterminated string", `Name == "x`},
+		{"missing operator", `Name "x"`},
+		{"missing value", `Name ==`},
+		{"unclosed paren", `(Name == "x"`},
+		{"trailing token", `Name == "x" )`},
+		{"invalid regex", `Name matches "("`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := parseFilter(tt.expr)
+			if err == nil {
+				if _, evalErr := expr.eval(&Service{}); evalErr == nil {
+					t.Fatalf("parseFilter(%q) succeeded, want error", tt.expr)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultHealthInterval is how often a service's health endpoint is
+	// polled when HEALTH_CHECK_INTERVAL is unset.
+	defaultHealthInterval = 5 * time.Second
+	// failureThreshold is the number of consecutive failed checks required
+	// before a service is marked unhealthy.
+	failureThreshold = 3
+	// successThreshold is the number of consecutive successful checks
+	// required before a degraded/unhealthy service is marked healthy again.
+	successThreshold = 2
+)
+
+// HealthEvent describes a service status transition, pushed to SSE
+// subscribers of GET /events.
+type HealthEvent struct {
+	Service   string    `json:"service"`
+	OldStatus string    `json:"old_status"`
+	NewStatus string    `json:"new_status"`
+	Timestamp time.Time `json:"timestamp"`
+	LatencyMs int64     `json:"latency_ms"`
+}
+
+// broadcaster fans out health transition events to any number of SSE
+// subscribers without blocking the watcher goroutines that publish them.
+type broadcaster struct {
+	mu   sync.Mutex
+	subs map[chan HealthEvent]struct{}
+}
+
+func newBroadcaster() *broadcaster {
+	return &broadcaster{subs: make(map[chan HealthEvent]struct{})}
+}
+
+func (b *broadcaster) subscribe() chan HealthEvent {
+	ch := make(chan HealthEvent, 16)
+	b.mu.Lock()
+	b.subs[ch] = struct{}{}
+	b.mu.Unlock()
+	return ch
+}
+
+func (b *broadcaster) unsubscribe(ch chan HealthEvent) {
+	b.mu.Lock()
+	delete(b.subs, ch)
+	b.mu.Unlock()
+	close(ch)
+}
+
+func (b *broadcaster) publish(event HealthEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch := range b.subs {
+		select {
+		case ch <- event:
+		default:
+			// Subscriber isn't keeping up; drop the event rather than
+			// block the watcher goroutine.
+		}
+	}
+}
+
+// watchService polls a single service's health endpoint on interval until
+// ctx is cancelled, updating its cached status and publishing a HealthEvent
+// whenever the status changes.
+func (g *Gateway) watchService(ctx context.Context, name string) {
+	ticker := time.NewTicker(g.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			g.pollOnce(name)
+		}
+	}
+}
+
+// pollOnce performs a single health check for name, updates its cached
+// status using the failure/success thresholds, and publishes a HealthEvent
+// if the status changed.
+func (g *Gateway) pollOnce(name string) {
+	g.mu.RLock()
+	service, exists := g.services[name]
+	var healthURL string
+	if exists {
+		healthURL = service.Health
+	}
+	g.mu.RUnlock()
+	if !exists {
+		return
+	}
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	start := time.Now()
+	resp, err := client.Get(healthURL)
+	latency := time.Since(start)
+
+	healthy := err == nil && resp.StatusCode == 200
+	if resp != nil {
+		resp.Body.Close()
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	oldStatus := service.Status
+	if healthy {
+		service.consecutiveFailures = 0
+		service.consecutiveSuccesses++
+		if service.Status != "healthy" && service.consecutiveSuccesses >= successThreshold {
+			service.Status = "healthy"
+		}
+	} else {
+		service.consecutiveSuccesses = 0
+		service.consecutiveFailures++
+		switch {
+		case service.consecutiveFailures >= failureThreshold:
+			service.Status = "unhealthy"
+		case service.Status == "healthy":
+			service.Status = "degraded"
+		}
+	}
+
+	if service.Status != oldStatus {
+		g.persistLocked()
+		g.events.publish(HealthEvent{
+			Service:   name,
+			OldStatus: oldStatus,
+			NewStatus: service.Status,
+			Timestamp: time.Now().UTC(),
+			LatencyMs: latency.Milliseconds(),
+		})
+	}
+}
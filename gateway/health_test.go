@@ -0,0 +1,106 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newHealthTestGateway(name, healthURL string) *Gateway {
+	return &Gateway{
+		services: map[string]*Service{
+			name: {Name: name, Status: "unknown", Health: healthURL},
+		},
+		events: newBroadcaster(),
+	}
+}
+
+func TestPollOnceMarksHealthyAfterSuccessThreshold(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	g := newHealthTestGateway("svc", srv.URL)
+
+	for i := 0; i < successThreshold-1; i++ {
+		g.pollOnce("svc")
+		if status := g.services["svc"].Status; status != "unknown" {
+			t.Fatalf("expected status to stay %q before the success threshold, got %q", "unknown", status)
+		}
+	}
+	g.pollOnce("svc")
+	if status := g.services["svc"].Status; status != "healthy" {
+		t.Fatalf("expected status %q after %d consecutive successes, got %q", "healthy", successThreshold, status)
+	}
+}
+
+func TestPollOnceMarksDegradedThenUnhealthy(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	g := newHealthTestGateway("svc", srv.URL)
+	g.services["svc"].Status = "healthy"
+
+	g.pollOnce("svc")
+	if status := g.services["svc"].Status; status != "degraded" {
+		t.Fatalf("expected a single failure from healthy to produce %q, got %q", "degraded", status)
+	}
+
+	for i := 1; i < failureThreshold; i++ {
+		g.pollOnce("svc")
+	}
+	if status := g.services["svc"].Status; status != "unhealthy" {
+		t.Fatalf("expected status %q after %d consecutive failures, got %q", "unhealthy", failureThreshold, status)
+	}
+}
+
+func TestPollOnceResetsFailureCountOnSuccess(t *testing.T) {
+	healthy := true
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if healthy {
+			w.WriteHeader(http.StatusOK)
+		} else {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer srv.Close()
+
+	g := newHealthTestGateway("svc", srv.URL)
+	g.services["svc"].Status = "healthy"
+
+	healthy = false
+	g.pollOnce("svc")
+	healthy = true
+	g.pollOnce("svc")
+
+	svc := g.services["svc"]
+	if svc.consecutiveFailures != 0 {
+		t.Errorf("expected consecutiveFailures to reset on success, got %d", svc.consecutiveFailures)
+	}
+	// A single success isn't enough to clear "degraded" on its own; it
+	// takes successThreshold consecutive successes (see pollOnce).
+	if svc.Status != "degraded" {
+		t.Errorf("expected status to stay %q until the success threshold is met, got %q", "degraded", svc.Status)
+	}
+}
+
+func TestPollOnceUnknownServiceIsNoop(t *testing.T) {
+	g := newHealthTestGateway("svc", "http://unused")
+	g.pollOnce("missing") // must not panic
+}
+
+func TestBroadcasterPublishDropsWhenSubscriberIsFull(t *testing.T) {
+	b := newBroadcaster()
+	ch := b.subscribe()
+	defer b.unsubscribe(ch)
+
+	for i := 0; i < cap(ch)+5; i++ {
+		b.publish(HealthEvent{Service: "svc"})
+	}
+	if len(ch) != cap(ch) {
+		t.Fatalf("expected the channel to be full at capacity %d, got %d", cap(ch), len(ch))
+	}
+}
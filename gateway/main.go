@@ -1,103 +1,319 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log"
-	"net/http"
 	"os"
+	"sync"
 	"time"
 
-	"github.com/gin-contrib/cors"
+	gwconfig "github.com/cmndcntrlcyber/offsec-team/gateway/config"
 	"github.com/gin-gonic/gin"
 )
 
 type Service struct {
-	Name   string `json:"name"`
-	URL    string `json:"url"`
-	Health string `json:"health"`
-	Status string `json:"status"`
+	Name      string   `json:"name"`
+	URL       string   `json:"url"`
+	Health    string   `json:"health"`
+	Status    string   `json:"status"`
+	Tags      []string `json:"tags,omitempty"`
+	Endpoints []string `json:"endpoints,omitempty"`
+
+	// Per-service proxy policy, set from the registration payload.
+	TimeoutMs  int `json:"timeout_ms"`
+	MaxRetries int `json:"max_retries"`
+	BackoffMs  int `json:"backoff_ms"`
+
+	// Circuit breaker state, surfaced read-only via GET /services.
+	Circuit      circuitState `json:"circuit"`
+	FailureCount int          `json:"failure_count"`
+
+	// consecutiveSuccesses/consecutiveFailures back the hysteresis in the
+	// background health watcher; see pollOnce in health.go.
+	consecutiveSuccesses int
+	consecutiveFailures  int
+	circuitOpenedAt      time.Time
 }
 
 type Gateway struct {
-	services map[string]*Service
+	mu        sync.RWMutex
+	services  map[string]*Service
+	storePath string
+
+	interval time.Duration
+	watchers map[string]context.CancelFunc
+	events   *broadcaster
+}
+
+// NewGateway creates a Gateway, loading any previously persisted service
+// registrations from storePath and starting a health watcher goroutine for
+// each of them. An empty storePath disables persistence.
+func NewGateway(storePath string, interval time.Duration) (*Gateway, error) {
+	services, err := loadServices(storePath)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &Gateway{
+		services:  services,
+		storePath: storePath,
+		interval:  interval,
+		watchers:  make(map[string]context.CancelFunc),
+		events:    newBroadcaster(),
+	}
+
+	for name := range services {
+		g.startWatcherLocked(name)
+	}
+	return g, nil
 }
 
-func NewGateway() *Gateway {
-	return &Gateway{
-		services: make(map[string]*Service),
+func (g *Gateway) RegisterService(name, url string, tags []string, policy ServicePolicy) *Service {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	policy = policy.withDefaults()
+	service := &Service{
+		Name:       name,
+		URL:        url,
+		Health:     fmt.Sprintf("%s/health", url),
+		Status:     "unknown",
+		Tags:       tags,
+		TimeoutMs:  policy.TimeoutMs,
+		MaxRetries: policy.MaxRetries,
+		BackoffMs:  policy.BackoffMs,
 	}
+	g.services[name] = service
+	g.persistLocked()
+	g.startWatcherLocked(name)
+	return service
 }
 
-func (g *Gateway) RegisterService(name, url string) {
-	g.services[name] = &Service{
-		Name:   name,
-		URL:    url,
-		Health: fmt.Sprintf("%s/health", url),
-		Status: "unknown",
+// startWatcherLocked launches the background poller for name. Callers must
+// hold g.mu. Any previous watcher for the same name is stopped first so
+// re-registration doesn't leak goroutines.
+func (g *Gateway) startWatcherLocked(name string) {
+	if cancel, exists := g.watchers[name]; exists {
+		cancel()
 	}
+	ctx, cancel := context.WithCancel(context.Background())
+	g.watchers[name] = cancel
+	go g.watchService(ctx, name)
 }
 
-func (g *Gateway) CheckHealth(name string) error {
+// UpdateService changes the URL and/or tags of an existing registration.
+func (g *Gateway) UpdateService(name, url string, tags []string, policy ServicePolicy) (*Service, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
 	service, exists := g.services[name]
 	if !exists {
+		return nil, fmt.Errorf("service %s not found", name)
+	}
+	if url != "" {
+		service.URL = url
+		service.Health = fmt.Sprintf("%s/health", url)
+	}
+	if tags != nil {
+		service.Tags = tags
+	}
+	if policy.TimeoutMs != 0 {
+		service.TimeoutMs = policy.TimeoutMs
+	}
+	if policy.MaxRetries != 0 {
+		service.MaxRetries = policy.MaxRetries
+	}
+	if policy.BackoffMs != 0 {
+		service.BackoffMs = policy.BackoffMs
+	}
+	g.persistLocked()
+	return service, nil
+}
+
+// DeregisterService removes a service from the registry and stops its
+// health watcher.
+func (g *Gateway) DeregisterService(name string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if _, exists := g.services[name]; !exists {
 		return fmt.Errorf("service %s not found", name)
 	}
+	delete(g.services, name)
+	if cancel, exists := g.watchers[name]; exists {
+		cancel()
+		delete(g.watchers, name)
+	}
+	g.persistLocked()
+	return nil
+}
 
-	client := &http.Client{Timeout: 5 * time.Second}
-	resp, err := client.Get(service.Health)
-	if err != nil {
-		service.Status = "unhealthy"
-		return err
+// persistLocked writes the current service table to disk. Callers must
+// hold g.mu. Persistence failures are logged rather than returned since
+// registration should not fail just because disk is briefly unavailable.
+func (g *Gateway) persistLocked() {
+	if err := saveServices(g.storePath, g.services); err != nil {
+		log.Printf("warning: failed to persist services: %v", err)
 	}
-	defer resp.Body.Close()
+}
+
+// GetServices returns a snapshot copy of the service table, reflecting
+// whatever status the background health watchers last observed.
+func (g *Gateway) GetServices() map[string]*Service {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
 
-	if resp.StatusCode == 200 {
-		service.Status = "healthy"
-	} else {
-		service.Status = "unhealthy"
+	snapshot := make(map[string]*Service, len(g.services))
+	for name, service := range g.services {
+		copied := *service
+		snapshot[name] = &copied
 	}
+	return snapshot
+}
 
-	return nil
+// getService returns a snapshot copy of a single service, if registered.
+func (g *Gateway) getService(name string) (*Service, bool) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	service, exists := g.services[name]
+	if !exists {
+		return nil, false
+	}
+	copied := *service
+	return &copied, true
 }
 
-func (g *Gateway) GetServices() map[string]*Service {
-	return g.services
+// GetServicesFiltered returns the subset of services matching the given
+// filter expression. An empty expr matches everything.
+func (g *Gateway) GetServicesFiltered(expr string) (map[string]*Service, error) {
+	all := g.GetServices()
+	if expr == "" {
+		return all, nil
+	}
+
+	parsed, err := parseFilter(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	matched := make(map[string]*Service)
+	for name, service := range all {
+		ok, err := parsed.eval(service)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			matched[name] = service
+		}
+	}
+	return matched, nil
+}
+
+// validatePolicyBounds rejects a registration/update request whose proxy
+// policy exceeds the gateway's sane maxima, rather than silently clamping
+// it: a caller who asked for a larger max_retries than we'll honor should
+// be told, not given a smaller value back without explanation. Zero values
+// are always allowed; they fall back to the package defaults.
+func validatePolicyBounds(timeoutMs, maxRetries, backoffMs int) error {
+	if timeoutMs > gwconfig.MaxTimeoutMs {
+		return fmt.Errorf("timeout_ms %d exceeds the limit of %d", timeoutMs, gwconfig.MaxTimeoutMs)
+	}
+	if maxRetries > gwconfig.MaxRetries {
+		return fmt.Errorf("max_retries %d exceeds the limit of %d", maxRetries, gwconfig.MaxRetries)
+	}
+	if backoffMs > gwconfig.MaxBackoffMs {
+		return fmt.Errorf("backoff_ms %d exceeds the limit of %d", backoffMs, gwconfig.MaxBackoffMs)
+	}
+	return nil
 }
 
 func main() {
 	gin.SetMode(gin.ReleaseMode)
 	r := gin.Default()
 
-	// CORS configuration
-	config := cors.DefaultConfig()
-	config.AllowOrigins = []string{
-		"https://chat.attck.nexus",
-		"https://tools.attck.nexus",
-		"https://researcher.c3s.nexus",
-		"https://mcp.c3s.nexus",
-		"https://rtpi.attck.nexus",
-		"http://localhost:3001",
-		"http://localhost:8001",
-		"http://localhost:8002",
-		"http://localhost:8003",
-		"http://localhost:8004",
-		"http://localhost:8005",
-	}
-	config.AllowMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"}
-	config.AllowHeaders = []string{"*"}
-	config.AllowCredentials = true
-
-	r.Use(cors.New(config))
-
-	gateway := NewGateway()
-
-	// Register services with updated URLs
-	gateway.RegisterService("chat-service", "http://chat-service:8080")
-	gateway.RegisterService("tools-service", "http://tools-service:8001")
-	gateway.RegisterService("research-service", "http://research-service:8002")
-	gateway.RegisterService("mcp-service", "http://mcp-service:8003")
-	gateway.RegisterService("rtpi-pen", "http://rtpi-pen:8080")
+	configPath := os.Getenv("GATEWAY_CONFIG")
+	if configPath == "" {
+		configPath = "gateway.yaml"
+	}
+	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+		if err := gwconfig.WriteStarter(configPath, gwconfig.Default()); err != nil {
+			log.Fatal("Failed to write starter gateway config:", err)
+		}
+	}
+	cfg, err := gwconfig.Load(configPath)
+	if err != nil {
+		log.Fatal("Failed to load gateway config:", err)
+	}
+
+	cors := newCORSHolder(cfg.CORSOrigins)
+	r.Use(cors.middleware())
+
+	authn, err := newAuthHolder(cfg.Auth)
+	if err != nil {
+		log.Fatal("Failed to initialize auth:", err)
+	}
+
+	storePath := os.Getenv("GATEWAY_SERVICES_FILE")
+	if storePath == "" {
+		storePath = "services.json"
+	}
+
+	healthInterval := defaultHealthInterval
+	if raw := os.Getenv("HEALTH_CHECK_INTERVAL"); raw != "" {
+		parsed, err := time.ParseDuration(raw)
+		if err != nil {
+			log.Fatal("Invalid HEALTH_CHECK_INTERVAL:", err)
+		}
+		healthInterval = parsed
+	}
+
+	gateway, err := NewGateway(storePath, healthInterval)
+	if err != nil {
+		log.Fatal("Failed to load service registry:", err)
+	}
+
+	tracingCfg := loadTracingConfig()
+	shutdownTracing, err := initTracing(context.Background(), tracingCfg)
+	if err != nil {
+		log.Fatal("Failed to initialize tracing:", err)
+	}
+	defer shutdownTracing(context.Background())
+
+	accessLog := newAccessLogger()
+
+	// Seed from the declarative config only if nothing was restored from
+	// the dynamic registration store.
+	if len(gateway.GetServices()) == 0 {
+		gateway.ReloadFromConfig(cfg)
+	}
+
+	reload := func() error {
+		reloaded, err := gwconfig.Load(configPath)
+		if err != nil {
+			return err
+		}
+		cors.set(reloaded.CORSOrigins)
+		authn.set(reloaded.Auth)
+		gateway.ReloadFromConfig(reloaded)
+		log.Printf("Reloaded gateway config from %s", configPath)
+		return nil
+	}
+
+	stopWatch := make(chan struct{})
+	defer close(stopWatch)
+	go watchConfigFile(configPath, stopWatch, func() {
+		if err := reload(); err != nil {
+			log.Printf("warning: config reload failed, keeping previous configuration: %v", err)
+		}
+	})
+
+	// Shared with the /admin/keys group below; built once so its
+	// GATEWAY_ADMIN_KEY warning is only logged a single time.
+	requireAdminKey := adminKeyMiddleware()
 
 	// Health check endpoint
 	r.GET("/health", func(c *gin.Context) {
@@ -110,143 +326,211 @@ func main() {
 		})
 	})
 
-	// Services endpoint with health checks
+	// Services endpoint, reading cached status from the background health
+	// watchers and optionally narrowed by ?filter=
 	r.GET("/services", func(c *gin.Context) {
-		// Check health of all services
-		for name := range gateway.GetServices() {
-			gateway.CheckHealth(name)
+		services, err := gateway.GetServicesFiltered(c.Query("filter"))
+		if err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
 		}
 
 		c.JSON(200, gin.H{
-			"services": gateway.GetServices(),
-			"total":    len(gateway.GetServices()),
+			"services": services,
+			"total":    len(services),
 			"gateway":  "service-gateway",
 		})
 	})
 
+	type serviceRequest struct {
+		Name       string   `json:"name"`
+		URL        string   `json:"url"`
+		Tags       []string `json:"tags"`
+		TimeoutMs  int      `json:"timeout_ms"`
+		MaxRetries int      `json:"max_retries"`
+		BackoffMs  int      `json:"backoff_ms"`
+	}
+
+	// Register a new service at runtime. Gated behind the admin key since
+	// the proxy resolves its upstream from this same registry: anyone who
+	// can register or update a service can redirect proxied traffic to an
+	// attacker-controlled host.
+	r.POST("/services", requireAdminKey, func(c *gin.Context) {
+		var req serviceRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+		if req.Name == "" || req.URL == "" {
+			c.JSON(400, gin.H{"error": "name and url are required"})
+			return
+		}
+		if err := validatePolicyBounds(req.TimeoutMs, req.MaxRetries, req.BackoffMs); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		policy := ServicePolicy{TimeoutMs: req.TimeoutMs, MaxRetries: req.MaxRetries, BackoffMs: req.BackoffMs}
+		service := gateway.RegisterService(req.Name, req.URL, req.Tags, policy)
+		c.JSON(201, gin.H{"service": service})
+	})
+
+	// Update an existing service's URL, tags, and/or proxy policy. Gated
+	// behind the admin key for the same reason as POST /services above.
+	r.PUT("/services/:name", requireAdminKey, func(c *gin.Context) {
+		var req serviceRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+		if err := validatePolicyBounds(req.TimeoutMs, req.MaxRetries, req.BackoffMs); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		policy := ServicePolicy{TimeoutMs: req.TimeoutMs, MaxRetries: req.MaxRetries, BackoffMs: req.BackoffMs}
+		service, err := gateway.UpdateService(c.Param("name"), req.URL, req.Tags, policy)
+		if err != nil {
+			c.JSON(404, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(200, gin.H{"service": service})
+	})
+
+	// Deregister a service. Gated behind the admin key for the same reason
+	// as POST /services above.
+	r.DELETE("/services/:name", requireAdminKey, func(c *gin.Context) {
+		if err := gateway.DeregisterService(c.Param("name")); err != nil {
+			c.JSON(404, gin.H{"error": err.Error()})
+			return
+		}
+		c.Status(204)
+	})
+
+	// Re-read the config file and atomically swap the service table and
+	// CORS config, without waiting for fsnotify to notice the change.
+	r.POST("/admin/reload", func(c *gin.Context) {
+		if err := reload(); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(200, gin.H{"services": gateway.GetServices()})
+	})
+
+	// Rotate static API keys without a restart. Protected separately from
+	// the rest of /admin by its own admin key, since it grants access to
+	// credentials rather than just configuration.
+	admin := r.Group("/admin", requireAdminKey)
+	registerAdminKeyRoutes(admin, authn.keys)
+
 	// Service discovery endpoint
 	r.GET("/discover", func(c *gin.Context) {
 		serviceEndpoints := make(map[string]interface{})
-		
+
 		for name, service := range gateway.GetServices() {
-			gateway.CheckHealth(name)
 			serviceEndpoints[name] = gin.H{
-				"url":    service.URL,
-				"health": service.Health,
-				"status": service.Status,
-				"endpoints": getServiceEndpoints(name),
+				"url":       service.URL,
+				"health":    service.Health,
+				"status":    service.Status,
+				"endpoints": service.Endpoints,
 			}
 		}
 
 		c.JSON(200, gin.H{
-			"services": serviceEndpoints,
+			"services":    serviceEndpoints,
 			"gateway_url": fmt.Sprintf("http://localhost:%s", os.Getenv("PORT")),
-			"timestamp": time.Now().UTC(),
+			"timestamp":   time.Now().UTC(),
 		})
 	})
 
-	// Proxy requests to services
-	r.Any("/proxy/:service/*path", func(c *gin.Context) {
+	// Server-sent stream of service health transitions.
+	r.GET("/events", func(c *gin.Context) {
+		ch := gateway.events.subscribe()
+		defer gateway.events.unsubscribe(ch)
+
+		c.Header("Content-Type", "text/event-stream")
+		c.Header("Cache-Control", "no-cache")
+		c.Header("Connection", "keep-alive")
+
+		c.Stream(func(w io.Writer) bool {
+			select {
+			case event, ok := <-ch:
+				if !ok {
+					return false
+				}
+				data, err := json.Marshal(event)
+				if err != nil {
+					return true
+				}
+				fmt.Fprintf(w, "data: %s\n\n", data)
+				return true
+			case <-c.Request.Context().Done():
+				return false
+			}
+		})
+	})
+
+	// Proxy requests to services, tracing and access-logging every hop.
+	// Tracing/access-logging runs first so a 401/403 from the auth
+	// middleware below it still gets a span and an access-log line instead
+	// of vanishing silently.
+	proxyGroup := r.Group("/proxy")
+	proxyGroup.Use(tracingMiddleware(gateway, accessLog))
+	proxyGroup.Use(authn.middleware())
+	proxyGroup.Any("/:service/*path", func(c *gin.Context) {
 		serviceName := c.Param("service")
 		path := c.Param("path")
 
-		service, exists := gateway.services[serviceName]
+		service, exists := gateway.getService(serviceName)
 		if !exists {
 			c.JSON(404, gin.H{"error": "Service not found"})
 			return
 		}
 
-		targetURL := fmt.Sprintf("%s%s", service.URL, path)
-
-		// Forward the request
-		client := &http.Client{Timeout: 30 * time.Second}
-		req, err := http.NewRequest(c.Request.Method, targetURL, c.Request.Body)
-		if err != nil {
-			c.JSON(500, gin.H{"error": err.Error()})
-			return
-		}
-
-		// Copy headers
-		for name, values := range c.Request.Header {
-			for _, value := range values {
-				req.Header.Add(name, value)
-			}
-		}
-
-		resp, err := client.Do(req)
-		if err != nil {
-			c.JSON(500, gin.H{"error": err.Error()})
-			return
-		}
-		defer resp.Body.Close()
-
-		// Copy response headers
-		for name, values := range resp.Header {
-			for _, value := range values {
-				c.Header(name, value)
-			}
-		}
-
-		c.Status(resp.StatusCode)
-		
-		var response interface{}
-		if err := json.NewDecoder(resp.Body).Decode(&response); err == nil {
-			c.JSON(resp.StatusCode, response)
-		}
+		gateway.proxyToService(c, service, path)
 	})
 
 	// Configuration endpoint
 	r.GET("/config", func(c *gin.Context) {
 		c.JSON(200, gin.H{
 			"cloudflare": gin.H{
-				"account_id": os.Getenv("CLOUDFLARE_ACCOUNT_ID"),
+				"account_id":    os.Getenv("CLOUDFLARE_ACCOUNT_ID"),
 				"worker_domain": os.Getenv("WORKER_DOMAIN"),
 			},
 			"services": gin.H{
-				"gateway_port": os.Getenv("PORT"),
-				"chat_port": "3001",
-				"tools_port": "8001",
+				"gateway_port":  os.Getenv("PORT"),
+				"chat_port":     "3001",
+				"tools_port":    "8001",
 				"research_port": "8002",
-				"mcp_port": "8003",
-				"rtpi_port": "8004",
+				"mcp_port":      "8003",
+				"rtpi_port":     "8004",
 			},
 			"database": gin.H{
 				"postgres_url": os.Getenv("POSTGRES_URL"),
-				"redis_url": os.Getenv("REDIS_URL"),
+				"redis_url":    os.Getenv("REDIS_URL"),
 			},
+			"tracing": tracingCfg,
 		})
 	})
 
 	// Start server with updated default port
 	port := os.Getenv("PORT")
 	if port == "" {
-		port = "8005"  // Changed from "8000" to "8005"
+		port = "8005" // Changed from "8000" to "8005"
 	}
 
 	log.Printf("üöÄ Service Gateway starting on port %s", port)
-	log.Printf("üìã Initialized %d services", len(gateway.services))
+	log.Printf("üìã Initialized %d services", len(gateway.GetServices()))
 	log.Printf("üåê Available endpoints:")
 	log.Printf("   - Health: http://localhost:%s/health", port)
 	log.Printf("   - Services: http://localhost:%s/services", port)
 	log.Printf("   - Discovery: http://localhost:%s/discover", port)
+	log.Printf("   - Events: http://localhost:%s/events", port)
 	log.Printf("   - Config: http://localhost:%s/config", port)
-	
+	log.Printf("   - Admin reload: http://localhost:%s/admin/reload", port)
+	log.Printf("   - Admin keys: http://localhost:%s/admin/keys", port)
+
 	if err := r.Run(":" + port); err != nil {
 		log.Fatal("Failed to start server:", err)
 	}
 }
-
-func getServiceEndpoints(serviceName string) []string {
-	endpoints := map[string][]string{
-		"chat-service": {"/health", "/openapi.json", "/execute", "/execute/contextual", "/researcher/callback"},
-		"tools-service": {"/health", "/agents", "/execute", "/openapi.json"},
-		"research-service": {"/health", "/api/research/status", "/api/research/search", "/api/research/analyze"},
-		"mcp-service": {"/health", "/api/mcp/status", "/api/mcp/context", "/api/mcp/models"},
-		"rtpi-pen": {"/health", "/api/rtpi", "/status"},
-	}
-	
-	if eps, exists := endpoints[serviceName]; exists {
-		return eps
-	}
-	return []string{"/health"}
-}
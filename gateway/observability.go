@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// tracingMiddleware starts an OTel span for each proxied request and emits
+// a structured JSON access log line once it completes, so traces and logs
+// can be correlated via trace_id.
+func tracingMiddleware(gateway *Gateway, accessLog *accessLogger) gin.HandlerFunc {
+	tracer := otel.Tracer(tracerName)
+
+	return func(c *gin.Context) {
+		start := time.Now()
+		serviceName := c.Param("service")
+
+		ctx, span := tracer.Start(c.Request.Context(), fmt.Sprintf("gateway.proxy %s", serviceName))
+		defer span.End()
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		duration := time.Since(start)
+		status := c.Writer.Status()
+
+		var upstreamURL string
+		if service, exists := gateway.getService(serviceName); exists {
+			upstreamURL = fmt.Sprintf("%s%s", service.URL, c.Param("path"))
+		}
+
+		span.SetAttributes(
+			attribute.String("http.method", c.Request.Method),
+			attribute.String("http.target", c.Request.URL.Path),
+			attribute.Int("http.status_code", status),
+			attribute.String("gateway.upstream_url", upstreamURL),
+		)
+		if status >= 500 {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+
+		accessLog.log(accessLogEntry{
+			Timestamp:     start.UTC(),
+			Service:       serviceName,
+			Method:        c.Request.Method,
+			Path:          c.Request.URL.Path,
+			UpstreamURL:   upstreamURL,
+			StatusCode:    status,
+			DurationMs:    duration.Milliseconds(),
+			ClientIP:      c.ClientIP(),
+			UserAgent:     c.Request.UserAgent(),
+			RequestBytes:  c.Request.ContentLength,
+			ResponseBytes: c.Writer.Size(),
+			TraceID:       span.SpanContext().TraceID().String(),
+		})
+	}
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// ServicePolicy holds the per-service proxy settings accepted by the
+// registration API. Zero values fall back to the package defaults.
+type ServicePolicy struct {
+	TimeoutMs  int `json:"timeout_ms"`
+	MaxRetries int `json:"max_retries"`
+	BackoffMs  int `json:"backoff_ms"`
+}
+
+const (
+	defaultTimeoutMs  = 30000
+	defaultMaxRetries = 2
+	defaultBackoffMs  = 100
+
+	// Upper bounds on the per-service proxy policy. Without these, a
+	// registration or config entry with an unreasonably large max_retries
+	// turns a single inbound request into an unbounded number of upstream
+	// round trips, tying up the serving goroutine and hammering the
+	// upstream — a self-inflicted DoS. These mirror config.MaxTimeoutMs,
+	// config.MaxRetries, and config.MaxBackoffMs.
+	maxTimeoutMs  = 120000
+	maxMaxRetries = 10
+	maxBackoffMs  = 10000
+)
+
+// withDefaults fills in zero-valued fields with the package defaults and
+// clamps any value outside the sane range (see maxTimeoutMs, maxMaxRetries,
+// maxBackoffMs above) down to that limit.
+func (p ServicePolicy) withDefaults() ServicePolicy {
+	switch {
+	case p.TimeoutMs <= 0:
+		p.TimeoutMs = defaultTimeoutMs
+	case p.TimeoutMs > maxTimeoutMs:
+		p.TimeoutMs = maxTimeoutMs
+	}
+	switch {
+	case p.MaxRetries <= 0:
+		p.MaxRetries = defaultMaxRetries
+	case p.MaxRetries > maxMaxRetries:
+		p.MaxRetries = maxMaxRetries
+	}
+	switch {
+	case p.BackoffMs <= 0:
+		p.BackoffMs = defaultBackoffMs
+	case p.BackoffMs > maxBackoffMs:
+		p.BackoffMs = maxBackoffMs
+	}
+	return p
+}
+
+// hopByHopHeaders lists the headers that must not be forwarded between a
+// proxy and the next hop, per RFC 7230 section 6.1.
+var hopByHopHeaders = []string{
+	"Connection",
+	"Keep-Alive",
+	"Proxy-Authenticate",
+	"Proxy-Authorization",
+	"Te",
+	"Trailer",
+	"Transfer-Encoding",
+	"Upgrade",
+}
+
+// stripHopByHopHeaders removes the standard hop-by-hop headers, plus any
+// additional ones the Connection header names, from h in place.
+func stripHopByHopHeaders(h http.Header) {
+	if connection := h.Get("Connection"); connection != "" {
+		for _, name := range strings.Split(connection, ",") {
+			h.Del(strings.TrimSpace(name))
+		}
+	}
+	for _, name := range hopByHopHeaders {
+		h.Del(name)
+	}
+}
+
+// proxyToService forwards the client request to the given service's
+// upstream, retrying on connect errors and 5xx responses with exponential
+// backoff, and recording the outcome against the service's circuit breaker.
+// A tripped breaker short-circuits the request with a 503 and Retry-After.
+// Response bodies are streamed back unchanged so binary, SSE, and chunked
+// payloads survive the round trip.
+func (g *Gateway) proxyToService(c *gin.Context, service *Service, path string) {
+	targetURL := fmt.Sprintf("%s%s", service.URL, path)
+
+	allowed, retryAfter := g.allowRequest(service.Name)
+	if !allowed {
+		c.Header("Retry-After", fmt.Sprintf("%.0f", retryAfter.Seconds()))
+		c.JSON(503, gin.H{"error": fmt.Sprintf("circuit open for service %s", service.Name)})
+		return
+	}
+
+	var bodyBytes []byte
+	if c.Request.Body != nil {
+		var err error
+		bodyBytes, err = io.ReadAll(c.Request.Body)
+		if err != nil {
+			g.recordResult(service.Name, false)
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	timeout := time.Duration(service.TimeoutMs) * time.Millisecond
+	backoff := time.Duration(service.BackoffMs) * time.Millisecond
+
+	var resp *http.Response
+	var cancel context.CancelFunc
+	var reqErr error
+
+	for attempt := 0; attempt <= service.MaxRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(backoff * time.Duration(uint(1)<<uint(attempt-1)))
+		}
+
+		var ctx context.Context
+		ctx, cancel = context.WithTimeout(c.Request.Context(), timeout)
+
+		req, err := http.NewRequestWithContext(ctx, c.Request.Method, targetURL, bytes.NewReader(bodyBytes))
+		if err != nil {
+			cancel()
+			g.recordResult(service.Name, false)
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		req.Header = c.Request.Header.Clone()
+		stripHopByHopHeaders(req.Header)
+		otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+		resp, reqErr = http.DefaultClient.Do(req)
+
+		isLastAttempt := attempt == service.MaxRetries
+		succeeded := reqErr == nil && resp.StatusCode < 500
+		if succeeded || isLastAttempt {
+			break
+		}
+
+		if resp != nil {
+			resp.Body.Close()
+		}
+		cancel()
+	}
+
+	if reqErr != nil {
+		cancel()
+		g.recordResult(service.Name, false)
+		c.JSON(502, gin.H{"error": reqErr.Error()})
+		return
+	}
+
+	g.recordResult(service.Name, resp.StatusCode < 500)
+	streamResponse(c, resp, cancel)
+}
+
+// streamResponse copies an upstream response's headers and body to the
+// client as they arrive, flushing after every write so long-lived
+// connections like SSE or chunked transfers aren't buffered.
+func streamResponse(c *gin.Context, resp *http.Response, cancel context.CancelFunc) {
+	defer cancel()
+	defer resp.Body.Close()
+
+	stripHopByHopHeaders(resp.Header)
+	for name, values := range resp.Header {
+		for _, value := range values {
+			c.Writer.Header().Add(name, value)
+		}
+	}
+	c.Writer.WriteHeader(resp.StatusCode)
+
+	w := io.Writer(c.Writer)
+	if flusher, ok := c.Writer.(http.Flusher); ok {
+		w = &flushWriter{w: c.Writer, flusher: flusher}
+	}
+	io.Copy(w, resp.Body)
+}
+
+// flushWriter flushes the underlying ResponseWriter after every write so
+// the client sees bytes as soon as the upstream sends them.
+type flushWriter struct {
+	w       io.Writer
+	flusher http.Flusher
+}
+
+func (fw *flushWriter) Write(p []byte) (int, error) {
+	n, err := fw.w.Write(p)
+	if n > 0 {
+		fw.flusher.Flush()
+	}
+	return n, err
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/cmndcntrlcyber/offsec-team/gateway/config"
+	"github.com/fsnotify/fsnotify"
+)
+
+// ReloadFromConfig merges the service topology declared in cfg into the
+// registry: every service named in cfg is (re)built from the file and
+// takes precedence over any same-named dynamic registration, but a
+// service registered at runtime via POST /services that cfg doesn't
+// mention is left untouched. This is what lets POST /services survive an
+// unrelated POST /admin/reload or fsnotify-triggered reload instead of
+// being silently dropped. Health watchers are restarted only for the
+// services cfg declares; dynamic services keep the watcher they already
+// have. Any *Service already handed out to an in-flight request stays
+// valid (it is just no longer reachable from the map), so swapping never
+// drops a request that's already in progress.
+func (g *Gateway) ReloadFromConfig(cfg *config.Config) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for _, svcCfg := range cfg.Services {
+		healthPath := svcCfg.HealthPath
+		if healthPath == "" {
+			healthPath = "/health"
+		}
+		policy := ServicePolicy{TimeoutMs: svcCfg.TimeoutMs, MaxRetries: svcCfg.MaxRetries, BackoffMs: svcCfg.BackoffMs}.withDefaults()
+
+		g.services[svcCfg.Name] = &Service{
+			Name:       svcCfg.Name,
+			URL:        svcCfg.URL,
+			Health:     fmt.Sprintf("%s%s", svcCfg.URL, healthPath),
+			Status:     "unknown",
+			Tags:       svcCfg.Tags,
+			Endpoints:  svcCfg.Endpoints,
+			TimeoutMs:  policy.TimeoutMs,
+			MaxRetries: policy.MaxRetries,
+			BackoffMs:  policy.BackoffMs,
+		}
+		g.startWatcherLocked(svcCfg.Name)
+	}
+
+	g.persistLocked()
+}
+
+// watchConfigFile calls reload whenever the file at path is written, until
+// stop is closed. A short debounce absorbs editors that write a file in
+// several small operations. fsnotify failures are logged and leave
+// auto-reload disabled; POST /admin/reload still works without it.
+func watchConfigFile(path string, stop <-chan struct{}, reload func()) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		log.Printf("warning: config auto-reload disabled, failed to start watcher: %v", err)
+		return
+	}
+	defer watcher.Close()
+
+	if err := watcher.Add(path); err != nil {
+		log.Printf("warning: config auto-reload disabled, failed to watch %s: %v", path, err)
+		return
+	}
+
+	debounce := time.NewTimer(time.Hour)
+	debounce.Stop()
+	defer debounce.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Op&(fsnotify.Write|fsnotify.Create) != 0 {
+				debounce.Reset(100 * time.Millisecond)
+			}
+		case <-debounce.C:
+			reload()
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			log.Printf("warning: config watcher error: %v", err)
+		}
+	}
+}
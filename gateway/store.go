@@ -0,0 +1,62 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// loadServices reads a previously persisted service table from path.
+// A missing file is not an error; it just means there is nothing to load yet.
+func loadServices(path string) (map[string]*Service, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]*Service{}, nil
+		}
+		return nil, fmt.Errorf("reading services file: %w", err)
+	}
+
+	var services map[string]*Service
+	if err := json.Unmarshal(data, &services); err != nil {
+		return nil, fmt.Errorf("parsing services file: %w", err)
+	}
+	return services, nil
+}
+
+// saveServices persists the service table to path, writing to a temporary
+// file first so a crash mid-write can't leave a truncated file behind.
+func saveServices(path string, services map[string]*Service) error {
+	if path == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(services, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling services: %w", err)
+	}
+
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, ".services-*.tmp")
+	if err != nil {
+		return fmt.Errorf("creating temp services file: %w", err)
+	}
+	tmpPath := tmp.Name()
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("writing temp services file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("closing temp services file: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("replacing services file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+)
+
+const tracerName = "gateway"
+
+// tracingConfig controls span sampling for the proxy. It is read once at
+// startup from the environment and surfaced (read-only) via GET /config.
+type tracingConfig struct {
+	Enabled bool    `json:"enabled"`
+	Ratio   float64 `json:"sampling_ratio"`
+}
+
+func loadTracingConfig() tracingConfig {
+	cfg := tracingConfig{Enabled: true, Ratio: 1.0}
+
+	if raw := os.Getenv("OTEL_TRACING_ENABLED"); raw != "" {
+		enabled, err := strconv.ParseBool(raw)
+		if err == nil {
+			cfg.Enabled = enabled
+		}
+	}
+	if raw := os.Getenv("OTEL_TRACES_SAMPLER_RATIO"); raw != "" {
+		ratio, err := strconv.ParseFloat(raw, 64)
+		if err == nil {
+			cfg.Ratio = ratio
+		}
+	}
+	return cfg
+}
+
+// initTracing wires up the global OTel tracer provider and W3C trace
+// context propagator. When OTEL_EXPORTER_OTLP_ENDPOINT is unset, tracing
+// stays on the no-op provider so the gateway runs unchanged without a
+// collector configured. The returned shutdown func flushes and stops the
+// exporter and must be called before the process exits.
+func initTracing(ctx context.Context, cfg tracingConfig) (func(context.Context) error, error) {
+	endpoint := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+	if endpoint == "" || !cfg.Enabled {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("creating OTLP exporter: %w", err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		semconv.ServiceName("service-gateway"),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("building resource: %w", err)
+	}
+
+	sampler := sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.Ratio))
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sampler),
+	)
+
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	return tp.Shutdown, nil
+}
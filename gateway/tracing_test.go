@@ -0,0 +1,33 @@
+package main
+
+import "testing"
+
+func TestLoadTracingConfigDefaults(t *testing.T) {
+	cfg := loadTracingConfig()
+	if !cfg.Enabled || cfg.Ratio != 1.0 {
+		t.Fatalf("expected tracing enabled with ratio 1.0 by default, got %+v", cfg)
+	}
+}
+
+func TestLoadTracingConfigReadsEnvOverrides(t *testing.T) {
+	t.Setenv("OTEL_TRACING_ENABLED", "false")
+	t.Setenv("OTEL_TRACES_SAMPLER_RATIO", "0.25")
+
+	cfg := loadTracingConfig()
+	if cfg.Enabled {
+		t.Error("expected OTEL_TRACING_ENABLED=false to disable tracing")
+	}
+	if cfg.Ratio != 0.25 {
+		t.Errorf("ratio = %v, want 0.25", cfg.Ratio)
+	}
+}
+
+func TestLoadTracingConfigIgnoresMalformedEnv(t *testing.T) {
+	t.Setenv("OTEL_TRACING_ENABLED", "not-a-bool")
+	t.Setenv("OTEL_TRACES_SAMPLER_RATIO", "not-a-float")
+
+	cfg := loadTracingConfig()
+	if !cfg.Enabled || cfg.Ratio != 1.0 {
+		t.Fatalf("expected malformed env vars to fall back to defaults, got %+v", cfg)
+	}
+}